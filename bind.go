@@ -0,0 +1,344 @@
+package pagination
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zheeeng/pagination/fieldsets"
+	"github.com/zheeeng/pagination/filters"
+	"github.com/zheeeng/pagination/sorting"
+)
+
+const defaultBindTag = "query"
+
+// Params receives the pagination state when it is embedded into the struct bound by Paginator::Bind
+type Params struct {
+	Page     int64
+	PageSize int64
+	Sort     sorting.Orders
+	Filter   filters.Expr
+	Fieldset fieldsets.Fields
+}
+
+var paramsType = reflect.TypeOf(Params{})
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// BindError reports a parameter that can't be bound or doesn't pass the validation
+type BindError struct {
+	Param  string
+	Reason string
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("pagination: parameter %q %s", e.Param, e.Reason)
+}
+
+// BindErrors aggregates the problems found by Binder::Bind
+type BindErrors []*BindError
+
+func (e BindErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Binder fills a struct from the parsed request. By default:
+//
+// -- Tag: "query", the fields are bound by their tags, e.g. `query:"year,min=1900"`
+//
+// -- DisallowUnknown: false, whether the query parameters not bound to any field are reported,
+// the parameters of the other namespaces are left to their own Paginators
+//
+// The tag holds the parameter name followed by the options, the name is resolved in the namespace of the Paginator,
+// e.g. "year" binds "orders.year" in the "orders" namespace:
+//
+// -- required: the parameter must be present
+//
+// -- min=N, max=N: bounds of a number or of the length of a string, they apply to each value of a slice
+//
+// -- oneof=a|b|c: the allowed values
+//
+// -- default=v: the value used when the parameter is absent
+//
+// Strings, booleans, numbers, time.Time (RFC 3339 or "2006-01-02"), their pointers and slices are supported,
+// a slice takes the repeated values of the parameter. Embedded structs are traversed,
+// an embedded Params receives the page, page size, sort, filter and fieldset of the Paginator.
+type Binder struct {
+	Tag             string
+	DisallowUnknown bool
+}
+
+type bindField struct {
+	param    string
+	required bool
+	min, max *float64
+	oneOf    []string
+	value    *string
+}
+
+func parseBindTag(tag string) (field bindField, err error) {
+	parts := strings.Split(tag, ",")
+	field.param = parts[0]
+
+	for _, option := range parts[1:] {
+		name, value := option, ""
+		if eq := strings.IndexByte(option, '='); eq >= 0 {
+			name, value = option[:eq], option[eq+1:]
+		}
+
+		switch name {
+		case "required":
+			field.required = true
+		case "min", "max":
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return field, fmt.Errorf("pagination: invalid tag option %q", option)
+			}
+			if name == "min" {
+				field.min = &bound
+			} else {
+				field.max = &bound
+			}
+		case "oneof":
+			field.oneOf = strings.Split(value, "|")
+		case "default":
+			field.value = &value
+		default:
+			return field, fmt.Errorf("pagination: unknown tag option %q", option)
+		}
+	}
+
+	return field, nil
+}
+
+// Bind fills the struct pointed by dst with the default Binder
+func (p *Paginator) Bind(dst interface{}) error {
+	return Binder{}.Bind(p, dst)
+}
+
+// Bind fills the struct pointed by dst from the Paginator, it returns BindErrors if any parameter is invalid,
+// the invalid sort, filter and fieldset parameters the Paginator has fallen back to defaults for included.
+// A malformed tag or dst which isn't a struct pointer is reported as a plain error.
+func (b Binder) Bind(p *Paginator, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pagination: bind destination must be a struct pointer, got %T", dst)
+	}
+
+	tag := b.Tag
+	if tag == "" {
+		tag = defaultBindTag
+	}
+
+	known := map[string]bool{p.params.Page: true, p.params.PageSize: true}
+	if p.sortParam != "" {
+		known[p.sortParam] = true
+	}
+	if p.filterParam != "" {
+		known[p.filterParam] = true
+		for _, key := range p.filterKeys {
+			known[key] = true
+		}
+	}
+	if p.fieldsetParam != "" {
+		known[p.fieldsetParam] = true
+	}
+
+	errs := append(BindErrors(nil), p.paramErrs...)
+	if err := b.bindStruct(p, v.Elem(), tag, known, &errs); err != nil {
+		return err
+	}
+
+	if b.DisallowUnknown {
+		var unknown []string
+		for param := range p.queries.Query {
+			if !known[param] && p.inNamespace(param) {
+				unknown = append(unknown, param)
+			}
+		}
+		sort.Strings(unknown)
+		for _, param := range unknown {
+			errs = append(errs, &BindError{param, "is unknown"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (b Binder) bindStruct(p *Paginator, v reflect.Value, tag string, known map[string]bool, errs *BindErrors) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if structField.Anonymous && structField.Type == paramsType {
+			nav := p.pager.GetNavigation()
+			fieldValue.Set(reflect.ValueOf(Params{
+				Page:     nav.Page,
+				PageSize: nav.PageSize,
+				Sort:     p.sort,
+				Filter:   p.filter,
+				Fieldset: p.fieldset,
+			}))
+			continue
+		}
+
+		raw, tagged := structField.Tag.Lookup(tag)
+		if !tagged {
+			if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+				if err := b.bindStruct(p, fieldValue, tag, known, errs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if raw == "-" || structField.PkgPath != "" {
+			continue
+		}
+
+		field, err := parseBindTag(raw)
+		if err != nil {
+			return err
+		}
+		if field.param == "" {
+			field.param = structField.Name
+		}
+		field.param = namespaced(p.namespace, field.param)
+		known[field.param] = true
+
+		values, ok := p.queries.Query[field.param]
+		if !ok || len(values) == 0 {
+			if field.required {
+				*errs = append(*errs, &BindError{field.param, "is required"})
+				continue
+			}
+			if field.value == nil {
+				continue
+			}
+			values = []string{*field.value}
+		}
+
+		if reason := bindValue(fieldValue, values, field); reason != "" {
+			*errs = append(*errs, &BindError{field.param, reason})
+		}
+	}
+
+	return nil
+}
+
+// bindValue converts and validates the values into v, it returns the reason of the failure
+func bindValue(v reflect.Value, values []string, field bindField) string {
+	if v.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if reason := setValue(slice.Index(i), value, field); reason != "" {
+				return reason
+			}
+		}
+		v.Set(slice)
+		return ""
+	}
+
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if reason := setValue(elem.Elem(), values[0], field); reason != "" {
+			return reason
+		}
+		v.Set(elem)
+		return ""
+	}
+
+	return setValue(v, values[0], field)
+}
+
+func setValue(v reflect.Value, value string, field bindField) string {
+	if len(field.oneOf) > 0 {
+		allowed := false
+		for _, option := range field.oneOf {
+			allowed = allowed || option == value
+		}
+		if !allowed {
+			return fmt.Sprintf("must be one of %s, got %q", strings.Join(field.oneOf, ", "), value)
+		}
+	}
+
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return fmt.Sprintf("has an invalid time %q", value)
+			}
+		}
+		v.Set(reflect.ValueOf(t))
+		return ""
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if reason := checkBounds(float64(len(value)), "length", field); reason != "" {
+			return reason
+		}
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Sprintf("has an invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("has an invalid integer %q", value)
+		}
+		if reason := checkBounds(float64(n), "value", field); reason != "" {
+			return reason
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("has an invalid unsigned integer %q", value)
+		}
+		if reason := checkBounds(float64(n), "value", field); reason != "" {
+			return reason
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("has an invalid number %q", value)
+		}
+		if reason := checkBounds(n, "value", field); reason != "" {
+			return reason
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Sprintf("can't be bound to %s", v.Type())
+	}
+
+	return ""
+}
+
+func checkBounds(n float64, what string, field bindField) string {
+	if field.min != nil && n < *field.min {
+		return fmt.Sprintf("%s must be at least %v, got %v", what, *field.min, n)
+	}
+	if field.max != nil && n > *field.max {
+		return fmt.Sprintf("%s must be at most %v, got %v", what, *field.max, n)
+	}
+
+	return ""
+}
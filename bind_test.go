@@ -0,0 +1,197 @@
+package pagination_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zheeeng/pagination"
+)
+
+type bookQuery struct {
+	pagination.Params
+	Author    string     `query:"author,required"`
+	Year      int        `query:"year,min=1900,max=2100"`
+	Price     *float64   `query:"price,min=0"`
+	Tags      []string   `query:"tag,max=8"`
+	Format    string     `query:"format,oneof=paper|ebook,default=paper"`
+	Available bool       `query:"available"`
+	Since     time.Time  `query:"since"`
+	Internal  string     `query:"-"`
+	Extra     extraQuery `query:"-"`
+	extraQuery
+}
+
+type extraQuery struct {
+	Publisher string `query:"publisher"`
+}
+
+func TestBind(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		Sort:     &pagination.SortConfiguration{Fields: []string{"year"}},
+		Filter:   &pagination.FilterConfiguration{Brackets: true},
+		Fieldset: &pagination.FieldsetConfiguration{Fields: []string{"author", "year"}},
+	})
+
+	price := 9.5
+	since := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		testName        string
+		link            string
+		disallowUnknown bool
+		want            bookQuery
+		errParams       []string
+	}{
+		{
+			"all fields",
+			"api.example.com/books?author=jk&year=2000&price=9.5&tag=a&tag=b&format=ebook&available=true&since=2018-01-02&publisher=tada&page=2&page_size=5&sort=-year",
+			true,
+			bookQuery{
+				Author: "jk", Year: 2000, Price: &price, Tags: []string{"a", "b"}, Format: "ebook", Available: true, Since: since,
+				extraQuery: extraQuery{"tada"},
+			},
+			nil,
+		},
+		{
+			"defaults",
+			"api.example.com/books?author=jk",
+			false,
+			bookQuery{Author: "jk", Format: "paper"},
+			nil,
+		},
+		{
+			"aggregated errors",
+			"api.example.com/books?year=1800&price=cheap&tag=abcdefghi&format=audio&available=maybe&since=tomorrow",
+			false,
+			bookQuery{},
+			[]string{"author", "year", "price", "tag", "format", "available", "since"},
+		},
+		{
+			"invalid sort, filter and fieldset",
+			"api.example.com/books?author=jk&sort=bogus&filter=year==&year[foo]=1&fields=isbn",
+			false,
+			bookQuery{},
+			[]string{"sort", "filter", "year[foo]", "fields"},
+		},
+		{
+			"unknown parameters",
+			"api.example.com/books?author=jk&isbn=1&Internal=x",
+			true,
+			bookQuery{Author: "jk", Format: "paper"},
+			[]string{"Internal", "isbn"},
+		},
+		{
+			"unknown parameters allowed",
+			"api.example.com/books?author=jk&isbn=1",
+			false,
+			bookQuery{Author: "jk", Format: "paper"},
+			nil,
+		},
+	}
+
+	for i, test := range tests {
+		pgt := pg.Parse(test.link)
+
+		var got bookQuery
+		err := pagination.Binder{DisallowUnknown: test.disallowUnknown}.Bind(pgt, &got)
+
+		var errParams []string
+		if err != nil {
+			errs, ok := err.(pagination.BindErrors)
+			if !ok {
+				t.Fatalf("%d. [%s] error: got %#v, want BindErrors", i, test.testName, err)
+			}
+			for _, e := range errs {
+				errParams = append(errParams, e.Param)
+			}
+		}
+		if !reflect.DeepEqual(errParams, test.errParams) {
+			t.Errorf("%d. [%s] error params: got %v, want %v (%v)", i, test.testName, errParams, test.errParams, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		nav := pgt.GetIndicator()
+		test.want.Params = pagination.Params{Page: nav.Page, PageSize: nav.PageSize, Sort: pgt.Sort(), Filter: pgt.Filter()}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d. [%s] bound: got %+v, want %+v", i, test.testName, got, test.want)
+		}
+	}
+
+	if got := (bookQuery{}); pg.Parse("api.example.com/books?author=jk&page=3").Bind(&got) != nil || got.Page != 3 || got.PageSize != 30 {
+		t.Errorf("params: got %+v, want page 3 and page size 30", got.Params)
+	}
+}
+
+func TestBindNamespace(t *testing.T) {
+	collections := pagination.NewCollections(map[string]pagination.PaginatorConfiguration{
+		"orders":  {Sort: &pagination.SortConfiguration{Fields: []string{"year"}}},
+		"tickets": {},
+	})
+	paginators := collections.Parse("api.example.com/dashboard?orders.author=jk&orders.page=2&orders.sort=-year&tickets.page=5&tickets.author=tolkien")
+
+	var orders bookQuery
+	if err := (pagination.Binder{DisallowUnknown: true}).Bind(paginators["orders"], &orders); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if orders.Author != "jk" || orders.Page != 2 || orders.Sort.String() != "-year" {
+		t.Errorf("orders: got %+v", orders)
+	}
+
+	var tickets struct {
+		pagination.Params
+		Author string `query:"author,required"`
+	}
+	if err := (pagination.Binder{DisallowUnknown: true}).Bind(paginators["tickets"], &tickets); err != nil {
+		t.Fatalf("tickets: %v", err)
+	}
+	if tickets.Author != "tolkien" || tickets.Page != 5 {
+		t.Errorf("tickets: got %+v", tickets)
+	}
+
+	err := (pagination.Binder{DisallowUnknown: true}).Bind(collections.Parse("api.example.com/dashboard?tickets.isbn=1")["tickets"], &tickets)
+	if errs, ok := err.(pagination.BindErrors); !ok || len(errs) != 2 || errs[0].Param != "tickets.author" || errs[1].Param != "tickets.isbn" {
+		t.Errorf("tickets errors: got %v, want the namespaced required author and unknown isbn", err)
+	}
+
+	err = pagination.Binder{}.Bind(collections.Parse("api.example.com/dashboard?orders.author=jk&orders.sort=bogus")["orders"], &orders)
+	if errs, ok := err.(pagination.BindErrors); !ok || len(errs) != 1 || errs[0].Param != "orders.sort" {
+		t.Errorf("orders errors: got %v, want the namespaced invalid sort", err)
+	}
+}
+
+func TestBindInvalidDestination(t *testing.T) {
+	pgt := pagination.DefaultPagination().Parse(requestURI)
+
+	var badTag struct {
+		Year int `query:"year,between=1|2"`
+	}
+	var badType struct {
+		Year map[string]int `query:"author"`
+	}
+
+	tests := []struct {
+		testName string
+		dst      interface{}
+		bindErrs bool
+	}{
+		{"not a pointer", struct{}{}, false},
+		{"not a struct", new(int), false},
+		{"nil pointer", (*bookQuery)(nil), false},
+		{"bad tag", &badTag, false},
+		{"unsupported type", &badType, true},
+	}
+
+	for i, test := range tests {
+		err := pgt.Bind(test.dst)
+		if err == nil {
+			t.Errorf("%d. [%s] expects an error", i, test.testName)
+			continue
+		}
+		if _, ok := err.(pagination.BindErrors); ok != test.bindErrs {
+			t.Errorf("%d. [%s] error: got %#v, want BindErrors: %v", i, test.testName, err, test.bindErrs)
+		}
+	}
+}
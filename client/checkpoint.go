@@ -0,0 +1,308 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint is the navigation state of the last completed page of a crawl
+type Checkpoint struct {
+	URL      string `json:"url"`
+	Next     string `json:"next"`
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"page_size"`
+	Cursor   string `json:"cursor,omitempty"`
+	Total    int64  `json:"total"`
+	HasTotal bool   `json:"has_total"`
+	// End is the offset following the last completed item,
+	// Items and Pages are the numbers of items and pages visited
+	End       int64     `json:"end"`
+	Items     int64     `json:"items"`
+	Pages     int64     `json:"pages"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore persists the checkpoints by crawl keys
+type CheckpointStore interface {
+	// Load returns the checkpoint of the key, ok is false if there isn't one
+	Load(key string) (cp Checkpoint, ok bool, err error)
+	Save(key string, cp Checkpoint) error
+	// Delete removes the checkpoint of the key, it isn't an error if there isn't one
+	Delete(key string) error
+}
+
+// MemoryStore keeps the checkpoints in memory, it is safe for concurrent use
+type MemoryStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: map[string]Checkpoint{}}
+}
+
+// Load returns the checkpoint of the key
+func (s *MemoryStore) Load(key string) (Checkpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp, ok := s.checkpoints[key]
+	return cp, ok, nil
+}
+
+// Save stores the checkpoint of the key
+func (s *MemoryStore) Save(key string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[key] = cp
+	return nil
+}
+
+// Delete removes the checkpoint of the key
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.checkpoints, key)
+	return nil
+}
+
+// FileStore keeps each checkpoint in a JSON file of the directory, the files are replaced atomically
+type FileStore struct {
+	Dir string
+}
+
+func (s FileStore) path(key string) string {
+	return filepath.Join(s.Dir, url.QueryEscape(key)+".json")
+}
+
+// Load reads the checkpoint file of the key
+func (s FileStore) Load(key string) (Checkpoint, bool, error) {
+	var cp Checkpoint
+
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return cp, false, nil
+	}
+	if err != nil {
+		return cp, false, err
+	}
+
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return cp, false, err
+	}
+
+	return cp, true, nil
+}
+
+// Save writes the checkpoint file of the key
+func (s FileStore) Save(key string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.Dir, ".checkpoint")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Delete removes the checkpoint file of the key
+func (s FileStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Window is a range of item offsets, numbered by the page it is reported with
+type Window struct {
+	Offset int64
+	Length int64
+}
+
+// Drift reports a page whose items don't continue the last completed page,
+// because the total changed or the page size isn't the same since the checkpoint.
+// The windows assume the items were added or removed before the crawled position, which is the worst case.
+type Drift struct {
+	Page          int64
+	PreviousTotal int64
+	Total         int64
+	// Skipped holds the items shifted before the page which are never visited,
+	// and Duplicated holds the items of the page which were visited already
+	Skipped    Window
+	Duplicated Window
+}
+
+// Crawler fetches all the pages of a resource and checkpoints each completed page, so a crawl resumes after a crash.
+//
+// -- Fetcher: the fetcher of the pages, a zero Fetcher is used if it is nil
+//
+// -- Store: the store of the checkpoints, a MemoryStore is used if it is nil
+//
+// -- Key: the key of the checkpoint, the start link is used if it is empty
+//
+// -- OnDrift: called when a page doesn't continue the last completed page
+//
+// The checkpoint is removed once the crawl completes.
+type Crawler struct {
+	Fetcher *Fetcher
+	Store   CheckpointStore
+	Key     string
+	OnDrift func(Drift)
+}
+
+// Checkpoint returns the checkpoint of the crawl starting from link, ok is false if it isn't started or it is completed
+func (c *Crawler) Checkpoint(link string) (cp Checkpoint, ok bool, err error) {
+	return c.store().Load(c.key(link))
+}
+
+func (c *Crawler) store() CheckpointStore {
+	if c.Store == nil {
+		c.Store = NewMemoryStore()
+	}
+
+	return c.Store
+}
+
+func (c *Crawler) key(link string) string {
+	if c.Key != "" {
+		return c.Key
+	}
+
+	return link
+}
+
+// Crawl fetches the pages from the checkpoint if there is one, or from link, visit is called with the pages in order.
+// The checkpoint is saved after visit returns nil for a page.
+func (c *Crawler) Crawl(ctx context.Context, link string, visit func(Page) error) error {
+	store, key := c.store(), c.key(link)
+
+	cp, resumed, err := store.Load(key)
+	if err != nil {
+		return err
+	}
+
+	start := link
+	if resumed && cp.Next != "" {
+		start = cp.Next
+	} else {
+		cp, resumed = Checkpoint{}, false
+	}
+
+	fetcher := c.Fetcher
+	if fetcher == nil {
+		fetcher = &Fetcher{}
+	}
+
+	err = fetcher.FetchAll(ctx, start, func(page Page) error {
+		offset := page.offset()
+		// the drift is only known for numbered pages
+		if resumed && page.Page > 0 && page.PageSize > 0 {
+			c.checkDrift(cp, page, offset)
+		}
+
+		if err := visit(page); err != nil {
+			return err
+		}
+
+		next := page.Links.Next
+		if next == "" {
+			if last, ok := page.lastPage(); ok && page.Page < last {
+				next = page.PageLink(page.Page + 1)
+			}
+		}
+		if !page.HasMore {
+			next = ""
+		}
+
+		cp = Checkpoint{
+			URL:       page.URL,
+			Next:      next,
+			Page:      page.Page,
+			PageSize:  page.PageSize,
+			Cursor:    page.NextCursor,
+			Total:     page.Total,
+			HasTotal:  page.HasTotal,
+			End:       offset + int64(len(page.Items)),
+			Items:     cp.Items + int64(len(page.Items)),
+			Pages:     cp.Pages + 1,
+			UpdatedAt: time.Now(),
+		}
+		resumed = true
+
+		return store.Save(key, cp)
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.Delete(key)
+}
+
+// offset returns the offset of the first item of the page
+func (nav Navigation) offset() int64 {
+	if nav.Page < nav.First || nav.PageSize <= 0 {
+		return 0
+	}
+
+	return (nav.Page - nav.First) * nav.PageSize
+}
+
+// checkDrift reports the drift of the page from the checkpoint of the last completed page
+func (c *Crawler) checkDrift(cp Checkpoint, page Page, offset int64) {
+	drift := Drift{Page: page.Page, PreviousTotal: cp.Total, Total: page.Total}
+
+	// the last completed item moved by the change of the total
+	expected := cp.End
+	if cp.HasTotal && page.HasTotal {
+		expected += page.Total - cp.Total
+	}
+	if expected < 0 {
+		expected = 0
+	}
+
+	switch {
+	case offset > expected:
+		drift.Skipped = Window{expected, offset - expected}
+	case offset < expected:
+		length := expected - offset
+		if items := int64(len(page.Items)); length > items && page.Items != nil {
+			length = items
+		}
+		drift.Duplicated = Window{offset, length}
+	}
+
+	totalChanged := cp.HasTotal && page.HasTotal && cp.Total != page.Total
+	if c.OnDrift != nil && (totalChanged || drift.Skipped.Length > 0 || drift.Duplicated.Length > 0) {
+		c.OnDrift(drift)
+	}
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+// driftServer serves the items paginated by this library, the items can be changed between the requests
+type driftServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	items items
+}
+
+func newDriftServer(n int) *driftServer {
+	s := &driftServer{items: make(items, n)}
+	for i := range s.items {
+		s.items[i] = item{i}
+	}
+
+	pg := pagination.DefaultPagination()
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		pgt := pg.Parse(r.URL.String())
+		json.NewEncoder(w).Encode(pgt.WrapWithTruncate(s.items, int64(len(s.items))))
+	}))
+
+	return s
+}
+
+func (s *driftServer) change(items items) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = items
+}
+
+func TestCrawlerResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		testName string
+		store    CheckpointStore
+		change   func(items) items
+		pages    []int64
+		drifts   []Drift
+	}{
+		{
+			"unchanged",
+			NewMemoryStore(),
+			func(s items) items { return s },
+			[]int64{3, 4, 5},
+			nil,
+		},
+		{
+			"added items",
+			FileStore{dir},
+			func(s items) items { return append(items{{-2}, {-1}}, s...) },
+			[]int64{3, 4, 5},
+			[]Drift{{Page: 3, PreviousTotal: 23, Total: 25, Duplicated: Window{10, 2}}},
+		},
+		{
+			"removed items",
+			FileStore{dir},
+			func(s items) items { return s[3:] },
+			[]int64{3, 4},
+			[]Drift{{Page: 3, PreviousTotal: 23, Total: 20, Skipped: Window{7, 3}}},
+		},
+	}
+
+	for i, test := range tests {
+		server := newDriftServer(23)
+		link := server.URL + "/items?page_size=5"
+
+		var drifts []Drift
+		crawler := &Crawler{Store: test.store, OnDrift: func(d Drift) { drifts = append(drifts, d) }}
+
+		crash := fmt.Errorf("crash")
+		err := crawler.Crawl(context.Background(), link, func(page Page) error {
+			if page.Page == 3 {
+				return crash
+			}
+			return nil
+		})
+		if err != crash {
+			t.Fatalf("%d. [%s] crawl: got %v, want the crash", i, test.testName, err)
+		}
+
+		cp, ok, err := crawler.Checkpoint(link)
+		if !ok || err != nil || cp.Page != 2 || cp.End != 10 || cp.Items != 10 || cp.Total != 23 {
+			t.Errorf("%d. [%s] checkpoint: got %+v, %v, %v", i, test.testName, cp, ok, err)
+		}
+
+		server.change(test.change(server.items))
+
+		var pages []int64
+		err = crawler.Crawl(context.Background(), link, func(page Page) error {
+			pages = append(pages, page.Page)
+			return nil
+		})
+		server.Close()
+
+		if err != nil {
+			t.Errorf("%d. [%s] resume: %v", i, test.testName, err)
+		}
+		if !reflect.DeepEqual(pages, test.pages) {
+			t.Errorf("%d. [%s] resumed pages: got %v, want %v", i, test.testName, pages, test.pages)
+		}
+		if !reflect.DeepEqual(drifts, test.drifts) {
+			t.Errorf("%d. [%s] drifts: got %+v, want %+v", i, test.testName, drifts, test.drifts)
+		}
+		if _, ok, _ := crawler.Checkpoint(link); ok {
+			t.Errorf("%d. [%s] checkpoint: expects it is removed once the crawl completes", i, test.testName)
+		}
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := FileStore{dir + "/nested"}
+	key := "https://api.example.com/items?page_size=5"
+
+	if _, ok, err := store.Load(key); ok || err != nil {
+		t.Errorf("load a missing checkpoint: got %v, %v", ok, err)
+	}
+
+	want := Checkpoint{URL: key, Next: key + "&page=3", Page: 2, PageSize: 5, Total: 23, HasTotal: true, End: 10, Items: 10, Pages: 2}
+	if err := store.Save(key, want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got, ok, err := store.Load(key); !ok || err != nil || got != want {
+		t.Errorf("load: got %+v, %v, %v", got, ok, err)
+	}
+
+	if err := store.Delete(key); err != nil {
+		t.Errorf("delete: %v", err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Errorf("delete a missing checkpoint: %v", err)
+	}
+}
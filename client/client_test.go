@@ -0,0 +1,193 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	links := ParseLinkHeader(
+		`<https://api.github.com/repos?page=3&per_page=100>; rel="next", <https://api.github.com/repos?page=50&per_page=100>; rel="last"`,
+		`<https://example.com/a,b>; rel="prev start"; title="a \"quoted\", title", <broken; rel=next`,
+	)
+
+	want := []Link{
+		{"https://api.github.com/repos?page=3&per_page=100", []string{"next"}, map[string]string{"rel": "next"}},
+		{"https://api.github.com/repos?page=50&per_page=100", []string{"last"}, map[string]string{"rel": "last"}},
+		{"https://example.com/a,b", []string{"prev", "start"}, map[string]string{"rel": "prev start", "title": `a "quoted", title`}},
+	}
+
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("links:\ngot  %#v\nwant %#v", links, want)
+	}
+	if !links[2].HasRel("START") || links[2].HasRel("next") {
+		t.Errorf("HasRel: unexpected relations of %#v", links[2])
+	}
+}
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://api.example.com/books?page=2&page_size=5")
+	zeroBase, _ := url.Parse("https://api.example.com/books?page=0&page_size=5")
+
+	tests := []struct {
+		testName string
+		base     *url.URL
+		header   http.Header
+		body     string
+		want     string
+	}{
+		{
+			"github link header",
+			nil,
+			http.Header{"Link": {`<https://api.github.com/repos?page=3&per_page=100>; rel="next", <https://api.github.com/repos?page=50&per_page=100>; rel="last", <https://api.github.com/repos?page=1&per_page=100>; rel="first", <https://api.github.com/repos?page=1&per_page=100>; rel="prev"`}},
+			`[{"id":1},{"id":2}]`,
+			"page=2 size=100 total=0/false first=1 last=50 prev=1 next=3 more=true next=https://api.github.com/repos?page=3&per_page=100 items=2",
+		},
+		{
+			"gitlab headers",
+			base,
+			http.Header{"X-Total": {"42"}, "X-Page": {"2"}, "X-Per-Page": {"20"}, "X-Total-Pages": {"3"}, "X-Next-Page": {"3"}, "X-Prev-Page": {"1"}},
+			``,
+			"page=2 size=20 total=42/true first=1 last=3 prev=1 next=3 more=true next= items=0",
+		},
+		{
+			"total count header and relative links",
+			base,
+			http.Header{"X-Total-Count": {"12"}, "Link": {`</books?page=3&page_size=5>; rel="next"`}},
+			`[]`,
+			"page=2 size=5 total=12/true first=1 last=3 prev=0 next=3 more=true next=https://api.example.com/books?page=3&page_size=5 items=0",
+		},
+		{
+			"pagination envelope",
+			base,
+			nil,
+			`{"pagination":{"page":2,"page_size":5,"total":12,"first":"/books?page=1&page_size=5","last":"/books?page=3&page_size=5","prev":"/books?page=1&page_size=5","next":"/books?page=3&page_size=5","query":{}},"result":[{"id":5}]}`,
+			"page=2 size=5 total=12/true first=1 last=3 prev=1 next=3 more=true next=https://api.example.com/books?page=3&page_size=5 items=1",
+		},
+		{
+			"pagination envelope without the last link",
+			base,
+			nil,
+			`{"pagination":{"page":2,"page_size":5,"total":12,"first":"/books?page=1&page_size=5","last":"","prev":"/books?page=1&page_size=5","next":"/books?page=3&page_size=5","query":{}},"result":[{"id":5}]}`,
+			"page=2 size=5 total=12/true first=1 last=3 prev=1 next=3 more=true next=https://api.example.com/books?page=3&page_size=5 items=1",
+		},
+		{
+			"pagination envelope of an unknown total",
+			base,
+			nil,
+			`{"pagination":{"page":2,"page_size":5,"total":0,"first":"/books?page=1&page_size=5","last":"","prev":"/books?page=1&page_size=5","next":"/books?page=3&page_size=5","query":{}},"result":[{"id":5}]}`,
+			"page=2 size=5 total=0/false first=1 last=0 prev=1 next=3 more=true next=https://api.example.com/books?page=3&page_size=5 items=1",
+		},
+		{
+			"zero-based pagination envelope",
+			zeroBase,
+			nil,
+			`{"pagination":{"page":0,"page_size":5,"total":12,"first":"/books?page=0&page_size=5","last":"/books?page=2&page_size=5","prev":"/books?page=0&page_size=5","next":"/books?page=1&page_size=5","query":{}},"result":[{"id":0}]}`,
+			"page=0 size=5 total=12/true first=0 last=2 prev=0 next=1 more=true next=https://api.example.com/books?page=1&page_size=5 items=1",
+		},
+		{
+			"zero-based pagination envelope without the last link",
+			nil,
+			nil,
+			`{"pagination":{"page":1,"page_size":5,"total":12,"first":"/books?page=0&page_size=5","last":"","prev":"/books?page=0&page_size=5","next":"/books?page=2&page_size=5","query":{}},"result":[{"id":5}]}`,
+			"page=1 size=5 total=12/true first=0 last=2 prev=0 next=2 more=true next=/books?page=2&page_size=5 items=1",
+		},
+		{
+			"zero-based pagination envelope of an unknown total",
+			nil,
+			nil,
+			`{"pagination":{"page":1,"page_size":5,"total":0,"first":"/books?page=0&page_size=5","last":"","prev":"/books?page=0&page_size=5","next":"/books?page=2&page_size=5","query":{}},"result":[{"id":5}]}`,
+			"page=1 size=5 total=0/false first=0 last=0 prev=0 next=2 more=true next=/books?page=2&page_size=5 items=1",
+		},
+		{
+			"empty pagination envelope",
+			base,
+			nil,
+			`{"pagination":{"page":1,"page_size":5,"total":0,"first":"/books?page=1&page_size=5","last":"","prev":"/books?page=1&page_size=5","next":"/books?page=2&page_size=5","query":{}},"result":[]}`,
+			"page=1 size=5 total=0/true first=1 last=0 prev=0 next=0 more=false next= items=0",
+		},
+		{
+			"empty list",
+			base,
+			http.Header{"X-Total-Count": {"0"}},
+			`{"pagination":{"page":1,"page_size":5,"total":0,"first":"/books?page=1&page_size=5","last":"","prev":"/books?page=1&page_size=5","next":"/books?page=2&page_size=5","query":{}},"result":[]}`,
+			"page=1 size=5 total=0/true first=1 last=0 prev=0 next=0 more=false next= items=0",
+		},
+		{
+			"pagination envelope on the last page",
+			base,
+			nil,
+			`{"pagination":{"page":3,"page_size":5,"total":12,"first":"/books?page=1&page_size=5","last":"/books?page=3&page_size=5","prev":"/books?page=2&page_size=5","next":"/books?page=3&page_size=5","query":{}},"result":[{"id":10}]}`,
+			"page=3 size=5 total=12/true first=1 last=3 prev=2 next=0 more=false next= items=1",
+		},
+		{
+			"drf envelope",
+			base,
+			nil,
+			`{"count":12,"next":null,"previous":"https://api.example.com/books?page=1&page_size=5","results":[{"id":5}]}`,
+			"page=2 size=5 total=12/true first=1 last=3 prev=1 next=0 more=false next= items=1",
+		},
+		{
+			"stripe envelope",
+			base,
+			nil,
+			`{"object":"list","url":"/v1/customers","has_more":true,"data":[{"id":"cus_1"},{"id":"cus_2"}]}`,
+			"page=2 size=5 total=0/false first=1 last=0 prev=0 next=0 more=true next= items=2 cursor=cus_2",
+		},
+		{
+			"stripe envelope on the last page",
+			base,
+			nil,
+			`{"has_more":false,"data":[{"id":3}]}`,
+			"page=2 size=5 total=0/false first=1 last=0 prev=0 next=0 more=false next= items=1",
+		},
+	}
+
+	for i, test := range tests {
+		nav, err := Parse(test.header, []byte(test.body), test.base)
+		if err != nil {
+			t.Errorf("%d. [%s] error: %v", i, test.testName, err)
+			continue
+		}
+
+		got := fmt.Sprintf("page=%d size=%d total=%d/%v first=%d last=%d prev=%d next=%d more=%v next=%s items=%d",
+			nav.Page, nav.PageSize, nav.Total, nav.HasTotal, nav.First, nav.Last, nav.Prev, nav.Next, nav.HasMore, nav.Links.Next, len(nav.Items))
+		if nav.NextCursor != "" {
+			got += " cursor=" + nav.NextCursor
+		}
+		if got != test.want {
+			t.Errorf("%d. [%s] navigation:\ngot  %s\nwant %s", i, test.testName, got, test.want)
+		}
+	}
+
+	if _, err := Parse(nil, []byte(`{"pagination":`), nil); err == nil {
+		t.Errorf("malformed body: expects an error")
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/books", nil)
+	resp := &http.Response{
+		Header:  http.Header{"Link": {`</books?page=2>; rel="next"`}},
+		Body:    ioutil.NopCloser(strings.NewReader(`[{"id":1}]`)),
+		Request: req,
+	}
+
+	nav, err := ParseResponse(resp)
+	if err != nil {
+		t.Fatalf("parse response: %v", err)
+	}
+	if nav.Links.Self != "https://api.example.com/books" || nav.Links.Next != "https://api.example.com/books?page=2" || nav.Page != 1 {
+		t.Errorf("navigation: got %+v", nav)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != `[{"id":1}]` {
+		t.Errorf("restored body: got %s", body)
+	}
+}
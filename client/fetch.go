@@ -0,0 +1,353 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zheeeng/pagination/pager"
+)
+
+const (
+	defaultConcurrency = 4
+	defaultBackoff     = 500 * time.Millisecond
+)
+
+// Page is a fetched page
+type Page struct {
+	Navigation
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// StatusError is returned when a page is responded with a non-2xx status
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       []byte
+	// RetryAfter is the delay requested by the Retry-After header, 0 if it is absent
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("client: %s responded %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Fetcher fetches all the pages of a paginated resource. By default:
+//
+// -- Client: http.DefaultClient
+//
+// -- Concurrency: 4, the maximum number of pages fetched at the same time
+//
+// -- Interval: 0, the minimum interval between the starts of two requests, 0 means no rate limit
+//
+// -- NewRequest: nil, builds the request of a page link, e.g. to add the authorization header
+//
+// -- Retries: 0, the number of retries of a page failed by a network error, a 429 or a 5xx status
+//
+// -- Backoff: 500ms, the delay before the first retry, it doubles for each retry, a Retry-After header overrides it
+//
+// When the first page reports the last page, directly by a `last` link or a last page number,
+// or by its total and page size, the links of the remaining pages are computed and fetched concurrently.
+// Otherwise the `next` links are followed one by one, until a page is empty or its page number doesn't advance.
+type Fetcher struct {
+	Client      *http.Client
+	Concurrency int
+	Interval    time.Duration
+	NewRequest  func(ctx context.Context, link string) (*http.Request, error)
+	Retries     int
+	Backoff     time.Duration
+}
+
+// PageLink returns the link of the page, which is computed by replacing the page parameter of a navigation link.
+// It returns an empty string if no link carries a known page parameter.
+func (nav Navigation) PageLink(page int64) string {
+	for _, link := range []string{nav.Links.Next, nav.Links.Last, nav.Links.Prev, nav.Links.First, nav.Links.Self} {
+		if link == "" {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+
+		query := u.Query()
+		for _, param := range pageParams {
+			if _, ok := query[param]; ok {
+				query.Set(param, strconv.FormatInt(page, 10))
+				u.RawQuery = query.Encode()
+				return u.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+// lastPage returns the last page number of the navigation, ok is false if it is unknown
+func (nav Navigation) lastPage() (last int64, ok bool) {
+	if nav.HasTotal && nav.PageSize > 0 {
+		if nav.Total == 0 {
+			return nav.Page, true
+		}
+		return pager.NewPagerWithBase(nav.Page, nav.PageSize, nav.First).SetTotal(nav.Total).GetNavigation().Last, true
+	}
+
+	return nav.Last, nav.Last > 0
+}
+
+// Fetch fetches a single page, the failures are retried up to Retries times
+func (f *Fetcher) Fetch(ctx context.Context, link string) (Page, error) {
+	backoff := f.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	for retry := 0; ; retry++ {
+		page, err := f.fetch(ctx, link)
+		if err == nil || retry >= f.Retries || !retryable(err) || ctx.Err() != nil {
+			return page, err
+		}
+
+		delay := backoff << uint(retry)
+		if e, ok := err.(*StatusError); ok && e.RetryAfter > 0 {
+			delay = e.RetryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return page, ctx.Err()
+		}
+	}
+}
+
+// retryable returns whether the failure may be temporary, the statuses other than 429 and 5xx and the malformed bodies aren't
+func retryable(err error) bool {
+	switch err := err.(type) {
+	case *StatusError:
+		return err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= 500
+	case *url.Error:
+		return true
+	}
+
+	return false
+}
+
+func (f *Fetcher) fetch(ctx context.Context, link string) (Page, error) {
+	var req *http.Request
+	var err error
+	if f.NewRequest != nil {
+		req, err = f.NewRequest(ctx, link)
+	} else {
+		req, err = http.NewRequest(http.MethodGet, link, nil)
+	}
+	if err != nil {
+		return Page{}, err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return Page{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Page{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		statusErr := &StatusError{URL: link, StatusCode: resp.StatusCode, Body: body}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return Page{}, statusErr
+	}
+
+	nav, err := Parse(resp.Header, body, req.URL)
+	if err != nil {
+		return Page{}, err
+	}
+
+	return Page{Navigation: nav, URL: link, Header: resp.Header, Body: body}, nil
+}
+
+// FetchAll fetches the first page by link and all the following pages, visit is called with the pages in order.
+// It stops at the first error of a fetch or of visit, the pending requests are canceled.
+func (f *Fetcher) FetchAll(ctx context.Context, link string, visit func(Page) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limit := &limiter{interval: f.Interval}
+
+	if err := limit.wait(ctx); err != nil {
+		return err
+	}
+	first, err := f.Fetch(ctx, link)
+	if err != nil {
+		return err
+	}
+	if err := visit(first); err != nil {
+		return err
+	}
+
+	if last, ok := first.lastPage(); ok && first.PageLink(first.Page) != "" {
+		return f.fetchRange(ctx, first, last, limit, visit)
+	}
+
+	for page := first; !page.ends(); {
+		if err := limit.wait(ctx); err != nil {
+			return err
+		}
+		next, err := f.Fetch(ctx, page.Links.Next)
+		if err != nil {
+			return err
+		}
+		if err := visit(next); err != nil {
+			return err
+		}
+		// a page which doesn't advance ends the crawl, a next link may keep returning it
+		if next.Links.Next == page.Links.Next || next.Page > 0 && page.Page > 0 && next.Page <= page.Page {
+			break
+		}
+		page = next
+	}
+
+	return nil
+}
+
+// ends returns whether no page follows by the next link, an empty page ends the next links of the unknown totals
+func (nav Navigation) ends() bool {
+	return nav.Links.Next == "" || !nav.HasMore || nav.Items != nil && len(nav.Items) == 0
+}
+
+type fetched struct {
+	page int64
+	Page
+	err error
+}
+
+// fetchRange fetches the pages after the first one up to last concurrently, and visits them in order
+func (f *Fetcher) fetchRange(ctx context.Context, first Page, last int64, limit *limiter, visit func(Page) error) error {
+	concurrency := f.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the pages fetched ahead of the visited one are bounded, so a slow page doesn't pile up the following ones
+	window := make(chan struct{}, 2*concurrency)
+	pages := make(chan int64)
+	results := make(chan fetched)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				result := fetched{page: page}
+				if result.err = limit.wait(ctx); result.err == nil {
+					result.Page, result.err = f.Fetch(ctx, first.PageLink(page))
+				}
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pages)
+		for page := first.Page + 1; page <= last; page++ {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case pages <- page:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := map[int64]fetched{}
+	next := first.Page + 1
+	for result := range results {
+		pending[result.page] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if result.err != nil {
+				return result.err
+			}
+			if err := visit(result.Page); err != nil {
+				return err
+			}
+			next++
+			<-window
+		}
+	}
+
+	return ctx.Err()
+}
+
+// limiter spaces the starts of the requests by an interval
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// wait blocks until the next request can start
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
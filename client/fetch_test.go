@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zheeeng/pagination"
+)
+
+type item struct {
+	ID int `json:"id"`
+}
+
+type items []item
+
+func (s items) Slice(start, end int) pagination.Truncatable { return s[start:end] }
+func (s items) Len() int                                    { return len(s) }
+
+// newServer serves 23 items paginated by this library, mode selects how the navigation is reported
+func newServer(mode string, inflight *int, maxInflight *int) *httptest.Server {
+	all := make(items, 23)
+	for i := range all {
+		all[i] = item{i}
+	}
+	pg := pagination.DefaultPagination()
+
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*inflight++
+		if *inflight > *maxInflight {
+			*maxInflight = *inflight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			*inflight--
+			mu.Unlock()
+		}()
+
+		pgt := pg.Parse(r.URL.String())
+		nav := pgt.GetIndicator()
+		// the later pages respond faster, so they complete out of order
+		time.Sleep(time.Duration(10-nav.Page) * 2 * time.Millisecond)
+
+		if mode == "fail" && nav.Page == 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		paginated := pgt.WrapWithTruncate(all, int64(len(all)))
+		switch mode {
+		case "sequential":
+			start, end := pgt.GetRange()
+			if end > int64(len(all)) {
+				end = int64(len(all))
+			}
+			if end < int64(len(all)) {
+				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, paginated.Pagination.Next))
+			}
+			json.NewEncoder(w).Encode(all[start:end])
+		case "link":
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, paginated.Pagination.Next, paginated.Pagination.Last))
+			json.NewEncoder(w).Encode(paginated.Result)
+		default:
+			json.NewEncoder(w).Encode(paginated)
+		}
+	}))
+}
+
+func TestFetchAll(t *testing.T) {
+	tests := []struct {
+		testName string
+		mode     string
+		pageSize int
+		pages    int
+		parallel bool
+		hasErr   bool
+	}{
+		{"total and page size", "envelope", 3, 8, true, false},
+		{"last link", "link", 5, 5, true, false},
+		{"next links", "sequential", 5, 5, false, false},
+		{"failed page", "fail", 3, 2, true, true},
+		{"single page", "envelope", 30, 1, false, false},
+	}
+
+	for i, test := range tests {
+		var inflight, maxInflight int
+		server := newServer(test.mode, &inflight, &maxInflight)
+
+		var got []int
+		var urls []string
+		err := (&Fetcher{Concurrency: 3}).FetchAll(context.Background(), server.URL+"/items?page_size="+strconv.Itoa(test.pageSize), func(page Page) error {
+			urls = append(urls, page.URL)
+			for _, raw := range page.Items {
+				var it item
+				if err := json.Unmarshal(raw, &it); err != nil {
+					return err
+				}
+				got = append(got, it.ID)
+			}
+			return nil
+		})
+		server.Close()
+
+		if (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+		}
+		if _, ok := err.(*StatusError); test.hasErr && !ok {
+			t.Errorf("%d. [%s] error: got %#v, want a *StatusError", i, test.testName, err)
+		}
+		if len(urls) != test.pages {
+			t.Errorf("%d. [%s] pages: got %d %v, want %d", i, test.testName, len(urls), urls, test.pages)
+		}
+		if test.hasErr {
+			continue
+		}
+
+		for id := range got {
+			if got[id] != id {
+				t.Errorf("%d. [%s] items: got %v, want 0 to 22 in order", i, test.testName, got)
+				break
+			}
+		}
+		if len(got) != 23 {
+			t.Errorf("%d. [%s] items: got %d, want 23", i, test.testName, len(got))
+		}
+		if parallel := maxInflight > 1; parallel != test.parallel || maxInflight > 3 {
+			t.Errorf("%d. [%s] max in flight: got %d, want parallel: %v within 3", i, test.testName, maxInflight, test.parallel)
+		}
+	}
+}
+
+func TestFetchAllInterval(t *testing.T) {
+	var inflight, maxInflight int
+	server := newServer("envelope", &inflight, &maxInflight)
+	defer server.Close()
+
+	started := time.Now()
+	var pages int
+	err := (&Fetcher{Concurrency: 4, Interval: 20 * time.Millisecond}).FetchAll(context.Background(), server.URL+"/items?page_size=5", func(page Page) error {
+		pages++
+		return nil
+	})
+	if err != nil || pages != 5 {
+		t.Fatalf("fetch all: got %d pages, %v", pages, err)
+	}
+	if elapsed := time.Since(started); elapsed < 80*time.Millisecond {
+		t.Errorf("interval: 5 requests took %v, want at least 80ms", elapsed)
+	}
+}
+
+func TestFetchAllVisitError(t *testing.T) {
+	var inflight, maxInflight int
+	server := newServer("envelope", &inflight, &maxInflight)
+	defer server.Close()
+
+	stop := fmt.Errorf("stop")
+	var pages int
+	err := (&Fetcher{}).FetchAll(context.Background(), server.URL+"/items?page_size=2", func(page Page) error {
+		if pages++; pages == 4 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || pages != 4 {
+		t.Errorf("visit error: got %v after %d pages, want stop after 4", err, pages)
+	}
+}
+
+func TestFetchAllUnknownTotal(t *testing.T) {
+	tests := []struct {
+		testName string
+		items    int
+		mode     string
+		requests int
+		want     int
+	}{
+		{"empty collection", 0, "envelope", 1, 0},
+		{"empty collection by link headers", 0, "link", 1, 0},
+		{"total-less collection", 23, "envelope", 6, 23},
+		{"total-less collection by link headers", 23, "link", 6, 23},
+		{"page not advancing", 23, "stuck", 2, 10},
+	}
+
+	for i, test := range tests {
+		all := make(items, test.items)
+		for id := range all {
+			all[id] = item{id}
+		}
+		pg := pagination.DefaultPagination()
+
+		var mu sync.Mutex
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+
+			link := r.URL.String()
+			if test.mode == "stuck" {
+				link = "/items?page_size=5"
+			}
+			// the total is unknown, so the next link is always the following page
+			paginated := pg.Parse(link).WrapWithTruncate(all, 0)
+			switch test.mode {
+			case "link":
+				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, paginated.Pagination.Next))
+				json.NewEncoder(w).Encode(paginated.Result)
+			case "stuck":
+				paginated.Pagination.Next += "&token=" + r.URL.Query().Get("page")
+				json.NewEncoder(w).Encode(paginated)
+			default:
+				json.NewEncoder(w).Encode(paginated)
+			}
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		var got int
+		err := (&Fetcher{}).FetchAll(ctx, server.URL+"/items?page_size=5", func(page Page) error {
+			got += len(page.Items)
+			return nil
+		})
+		cancel()
+		server.Close()
+
+		if err != nil {
+			t.Errorf("%d. [%s] error: %v", i, test.testName, err)
+		}
+		if requests != test.requests {
+			t.Errorf("%d. [%s] requests: got %d, want %d", i, test.testName, requests, test.requests)
+		}
+		if got != test.want {
+			t.Errorf("%d. [%s] items: got %d, want %d", i, test.testName, got, test.want)
+		}
+	}
+}
+
+func TestPageLink(t *testing.T) {
+	nav := Navigation{Links: Links{Self: "https://api.example.com/items", Next: "https://api.example.com/items?per_page=100&page=2"}}
+	if got, want := nav.PageLink(7), "https://api.example.com/items?page=7&per_page=100"; got != want {
+		t.Errorf("page link: got %s, want %s", got, want)
+	}
+	if got := (Navigation{Links: Links{Next: "/items/page/2"}}).PageLink(7); got != "" {
+		t.Errorf("page link without page parameter: got %s, want empty", got)
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	tests := []struct {
+		testName string
+		status   int
+		retries  int
+		requests int
+		hasErr   bool
+	}{
+		{"recovered", http.StatusServiceUnavailable, 2, 3, false},
+		{"exhausted", http.StatusServiceUnavailable, 1, 2, true},
+		{"too many requests", http.StatusTooManyRequests, 2, 3, false},
+		{"not retryable", http.StatusNotFound, 2, 1, true},
+	}
+
+	for i, test := range tests {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests++; requests <= 2 {
+				w.WriteHeader(test.status)
+				return
+			}
+			w.Write([]byte(`[{"id":1}]`))
+		}))
+
+		page, err := (&Fetcher{Retries: test.retries, Backoff: time.Millisecond}).Fetch(context.Background(), server.URL)
+		server.Close()
+
+		if (err != nil) != test.hasErr || requests != test.requests {
+			t.Errorf("%d. [%s] fetch: got %v after %d requests, want error: %v after %d", i, test.testName, err, requests, test.hasErr, test.requests)
+		}
+		if !test.hasErr && len(page.Items) != 1 {
+			t.Errorf("%d. [%s] items: got %d, want 1", i, test.testName, len(page.Items))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"strings"
+)
+
+// Link is a RFC 8288 web link of a Link header
+type Link struct {
+	URL    string
+	Rel    []string
+	Params map[string]string
+}
+
+// HasRel returns whether the link has the relation type, it is compared case-insensitively
+func (l Link) HasRel(rel string) bool {
+	for _, r := range l.Rel {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitOutside splits s by sep, except the separators quoted or enclosed in angle brackets
+func splitOutside(s string, sep byte) []string {
+	var parts []string
+	quoted, bracketed, escaped := false, false, false
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"' && !bracketed:
+			quoted = !quoted
+		case c == '<' && !quoted:
+			bracketed = true
+		case c == '>' && !quoted:
+			bracketed = false
+		case c == sep && !quoted && !bracketed:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+func unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+
+	value = value[1 : len(value)-1]
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		buf.WriteByte(value[i])
+	}
+
+	return buf.String()
+}
+
+// ParseLinkHeader parses the values of Link headers, e.g. `<https://api.example.com/books?page=2>; rel="next"`.
+// The malformed links are skipped, the relation types are lowercased.
+func ParseLinkHeader(values ...string) []Link {
+	var links []Link
+
+	for _, value := range values {
+		for _, raw := range splitOutside(value, ',') {
+			params := splitOutside(strings.TrimSpace(raw), ';')
+
+			target := strings.TrimSpace(params[0])
+			if len(target) < 2 || target[0] != '<' || target[len(target)-1] != '>' {
+				continue
+			}
+
+			link := Link{URL: target[1 : len(target)-1], Params: map[string]string{}}
+			for _, param := range params[1:] {
+				param = strings.TrimSpace(param)
+				if param == "" {
+					continue
+				}
+
+				name, value := param, ""
+				if eq := strings.IndexByte(param, '='); eq >= 0 {
+					name, value = strings.TrimSpace(param[:eq]), unquote(strings.TrimSpace(param[eq+1:]))
+				}
+				name = strings.ToLower(name)
+
+				if _, ok := link.Params[name]; ok {
+					continue
+				}
+				link.Params[name] = value
+
+				if name == "rel" {
+					link.Rel = strings.Fields(strings.ToLower(value))
+				}
+			}
+
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
@@ -0,0 +1,354 @@
+// Package client reads the pagination of API responses, and walks their pages.
+//
+// The navigation is normalized from:
+//
+// -- RFC 8288 Link headers, e.g. GitHub's `Link: <...?page=3>; rel="next", <...?page=34>; rel="last"`
+//
+// -- total and page headers, e.g. `X-Total-Count`, and GitLab's `X-Total`, `X-Page`, `X-Per-Page`, `X-Next-Page`
+//
+// -- the envelope of this library, `{"pagination": {...}, "result": [...]}`
+//
+// -- Django REST Framework's `{count, next, previous, results}` and Stripe's `{data, has_more}` envelopes
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zheeeng/pagination/pager"
+)
+
+// Links defines the navigation links of a response, a missing link is empty
+type Links struct {
+	Self  string
+	First string
+	Last  string
+	Prev  string
+	Next  string
+}
+
+// Navigation is the normalized pagination of a response.
+// The page numbers are the ones reported by the API, they are 0 if they are unknown,
+// and Total is only meaningful if HasTotal is true.
+type Navigation struct {
+	pager.Navigation
+	HasTotal   bool
+	HasMore    bool
+	Links      Links
+	NextCursor string
+	PrevCursor string
+	// Items holds the items of the response body, it is nil if they aren't found
+	Items []json.RawMessage
+}
+
+// pageParams and pageSizeParams are the query parameters the page numbers are read from
+var (
+	pageParams     = []string{"page", "p"}
+	pageSizeParams = []string{"page_size", "per_page", "limit", "size", "pageSize"}
+)
+
+// ParseResponse reads the navigation of the response, the body is read and restored for further reading.
+// The relative links are resolved against the request URL.
+func ParseResponse(resp *http.Response) (Navigation, error) {
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(resp.Body); err != nil {
+			return Navigation{}, err
+		}
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	var base *url.URL
+	if resp.Request != nil {
+		base = resp.Request.URL
+	}
+
+	return Parse(resp.Header, body, base)
+}
+
+// Parse reads the navigation of the response headers and body, the relative links are resolved against base if it is not nil.
+// The body is only inspected if it is a JSON object or array, an error is returned if it is malformed JSON.
+func Parse(header http.Header, body []byte, base *url.URL) (Navigation, error) {
+	var nav Navigation
+
+	nav.parseHeader(header)
+	if err := nav.parseBody(body); err != nil {
+		return nav, err
+	}
+
+	nav.resolve(base)
+	nav.complete()
+
+	return nav, nil
+}
+
+func headerInt(header http.Header, names ...string) (int64, bool) {
+	for _, name := range names {
+		if value := strings.TrimSpace(header.Get(name)); value != "" {
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return n, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func (nav *Navigation) parseHeader(header http.Header) {
+	for _, link := range ParseLinkHeader(header["Link"]...) {
+		switch {
+		case link.HasRel("next"):
+			nav.Links.Next = link.URL
+		case link.HasRel("prev"), link.HasRel("previous"):
+			nav.Links.Prev = link.URL
+		case link.HasRel("first"):
+			nav.Links.First = link.URL
+		case link.HasRel("last"):
+			nav.Links.Last = link.URL
+		case link.HasRel("self"):
+			nav.Links.Self = link.URL
+		}
+	}
+
+	if total, ok := headerInt(header, "X-Total-Count", "X-Total"); ok {
+		nav.Total, nav.HasTotal = total, true
+	}
+	if page, ok := headerInt(header, "X-Page", "X-Current-Page"); ok {
+		nav.Page = page
+	}
+	if pageSize, ok := headerInt(header, "X-Per-Page", "X-Page-Size"); ok {
+		nav.PageSize = pageSize
+	}
+	if last, ok := headerInt(header, "X-Total-Pages", "X-Page-Count"); ok {
+		nav.Last = last
+	}
+	if next, ok := headerInt(header, "X-Next-Page"); ok {
+		nav.Next = next
+	}
+	if prev, ok := headerInt(header, "X-Prev-Page"); ok {
+		nav.Prev = prev
+	}
+	if cursor := header.Get("X-Next-Cursor"); cursor != "" {
+		nav.NextCursor = cursor
+	}
+}
+
+// envelope holds the fields of the supported envelopes
+type envelope struct {
+	Pagination *struct {
+		Page     int64  `json:"page"`
+		PageSize int64  `json:"page_size"`
+		Total    *int64 `json:"total"`
+		First    string `json:"first"`
+		Last     string `json:"last"`
+		Prev     string `json:"prev"`
+		Next     string `json:"next"`
+	} `json:"pagination"`
+	Result []json.RawMessage `json:"result"`
+
+	Count    *int64            `json:"count"`
+	Next     *string           `json:"next"`
+	Previous *string           `json:"previous"`
+	Results  []json.RawMessage `json:"results"`
+
+	HasMore    *bool             `json:"has_more"`
+	Data       []json.RawMessage `json:"data"`
+	NextCursor string            `json:"next_cursor"`
+	PrevCursor string            `json:"prev_cursor"`
+}
+
+func (nav *Navigation) parseBody(body []byte) error {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+
+	switch body[0] {
+	case '[':
+		return json.Unmarshal(body, &nav.Items)
+	case '{':
+	default:
+		return nil
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return err
+	}
+
+	if pg := env.Pagination; pg != nil {
+		nav.Page, nav.PageSize = pg.Page, pg.PageSize
+		// a zero total of a non-empty page is the unknown total of this library
+		if pg.Total != nil && (*pg.Total > 0 || len(env.Result) == 0) {
+			nav.Total, nav.HasTotal = *pg.Total, true
+		}
+		nav.Links = Links{First: pg.First, Last: pg.Last, Prev: pg.Prev, Next: pg.Next}
+		nav.Items = env.Result
+
+		// the links of this library clamp prev and next to the first and last pages
+		if pg.Next != "" && pg.Next == pg.Last && nav.pageOf(pg.Last) == nav.Page {
+			nav.Links.Next = ""
+		}
+		if pg.Prev != "" && pg.Prev == pg.First && nav.pageOf(pg.First) == nav.Page {
+			nav.Links.Prev = ""
+		}
+	}
+
+	if env.Count != nil {
+		nav.Total, nav.HasTotal = *env.Count, true
+	}
+	if env.Next != nil {
+		nav.Links.Next = *env.Next
+	}
+	if env.Previous != nil {
+		nav.Links.Prev = *env.Previous
+	}
+	if env.Results != nil {
+		nav.Items = env.Results
+	}
+
+	if env.Data != nil && nav.Items == nil {
+		nav.Items = env.Data
+	}
+	if env.HasMore != nil {
+		nav.HasMore = *env.HasMore
+		if nav.HasMore && env.NextCursor == "" && len(env.Data) > 0 {
+			var item struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(env.Data[len(env.Data)-1], &item) == nil && len(item.ID) > 0 {
+				var id string
+				if json.Unmarshal(item.ID, &id) != nil {
+					id = string(item.ID)
+				}
+				nav.NextCursor = id
+			}
+		}
+	}
+	if env.NextCursor != "" {
+		nav.NextCursor = env.NextCursor
+	}
+	if env.PrevCursor != "" {
+		nav.PrevCursor = env.PrevCursor
+	}
+
+	return nil
+}
+
+func resolve(base *url.URL, link string) string {
+	if base == nil || link == "" {
+		return link
+	}
+
+	ref, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+func (nav *Navigation) resolve(base *url.URL) {
+	if nav.Links.Self == "" && base != nil {
+		nav.Links.Self = base.String()
+	}
+
+	nav.Links.Self = resolve(base, nav.Links.Self)
+	nav.Links.First = resolve(base, nav.Links.First)
+	nav.Links.Last = resolve(base, nav.Links.Last)
+	nav.Links.Prev = resolve(base, nav.Links.Prev)
+	nav.Links.Next = resolve(base, nav.Links.Next)
+}
+
+// queryInt returns the first integer query parameter of the link among the names
+func queryInt(link string, names []string) (int64, bool) {
+	if link == "" {
+		return 0, false
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return 0, false
+	}
+
+	query := u.Query()
+	for _, name := range names {
+		if n, err := strconv.ParseInt(query.Get(name), 10, 64); err == nil {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+func (nav *Navigation) pageOf(link string) int64 {
+	page, _ := queryInt(link, pageParams)
+	return page
+}
+
+// zeroBased returns whether the first, self or prev link numbers a page 0
+func (nav *Navigation) zeroBased() bool {
+	for _, link := range []string{nav.Links.First, nav.Links.Self, nav.Links.Prev} {
+		if page, ok := queryInt(link, pageParams); ok && page == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// complete derives the unknown page numbers from the links and the totals
+func (nav *Navigation) complete() {
+	fill := func(n *int64, link string) {
+		if *n == 0 {
+			*n = nav.pageOf(link)
+		}
+	}
+	fill(&nav.Page, nav.Links.Self)
+	fill(&nav.First, nav.Links.First)
+	fill(&nav.Last, nav.Links.Last)
+	fill(&nav.Prev, nav.Links.Prev)
+	fill(&nav.Next, nav.Links.Next)
+
+	if nav.PageSize == 0 {
+		for _, link := range []string{nav.Links.Self, nav.Links.Next, nav.Links.Prev, nav.Links.First, nav.Links.Last} {
+			if pageSize, ok := queryInt(link, pageSizeParams); ok {
+				nav.PageSize = pageSize
+				break
+			}
+		}
+	}
+
+	if nav.Page == 0 {
+		switch {
+		case nav.Next > 0:
+			nav.Page = nav.Next - 1
+		case nav.Prev > 0:
+			nav.Page = nav.Prev + 1
+		}
+	}
+	// the pages are numbered from 1 unless a link numbers a page 0
+	if nav.First == 0 && !nav.zeroBased() && (nav.Page > 0 || nav.Last > 0 || nav.HasTotal && nav.Total > 0) {
+		nav.First = 1
+	}
+	if nav.Last == 0 && nav.HasTotal && nav.Total > 0 && nav.PageSize > 0 {
+		nav.Last = nav.First + (nav.Total+nav.PageSize-1)/nav.PageSize - 1
+	}
+
+	// an empty list has no next page, whatever the links say
+	if nav.HasTotal && nav.Total == 0 {
+		nav.Links.Next, nav.Next, nav.HasMore = "", 0, false
+		return
+	}
+
+	if !nav.HasMore {
+		nav.HasMore = nav.Links.Next != "" || nav.NextCursor != "" || nav.Page > 0 && nav.Next > nav.Page
+	}
+}
@@ -0,0 +1,407 @@
+// Command pagefetch fetches all the pages of a paginated API and writes the items as NDJSON, CSV or a JSON array.
+//
+// The pages are navigated by the `pagination`/`result` envelope of this library, `Link` headers,
+// and the other envelopes and headers read by the client package:
+//
+//	pagefetch -format csv -concurrency 8 -retries 3 -o books.csv "https://api.example.com/books?page_size=100"
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/zheeeng/pagination/client"
+)
+
+// headers collects the repeated -H flags
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headers) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("header %q must be like \"Name: value\"", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("pagefetch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: pagefetch [flags] <start url>")
+		flags.PrintDefaults()
+	}
+
+	var header headers
+	format := flags.String("format", "ndjson", "output format: ndjson, csv or json")
+	output := flags.String("o", "", "output file, the standard output by default")
+	concurrency := flags.Int("concurrency", 4, "maximum number of pages fetched at the same time")
+	retries := flags.Int("retries", 2, "retries of a page failed by a network error, a 429 or a 5xx status")
+	backoff := flags.Duration("backoff", 500*time.Millisecond, "delay before the first retry, it doubles for each retry")
+	interval := flags.Duration("interval", 0, "minimum interval between the starts of two requests")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of a request")
+	checkpoint := flags.String("checkpoint", "", "directory of the checkpoints, an interrupted fetch resumes from it and appends to the -o file")
+	quiet := flags.Bool("quiet", false, "don't report the progress")
+	flags.Var(&header, "H", "request header like \"Authorization: Bearer token\", repeatable")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	newWriter, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "pagefetch: unknown format %q\n", *format)
+		return 2
+	}
+	// a JSON array can't be continued by appending the items of a resumed fetch
+	if *format == "json" && *checkpoint != "" {
+		fmt.Fprintln(stderr, "pagefetch: -format json can't be resumed, use ndjson or csv with -checkpoint")
+		return 2
+	}
+
+	var crawler *client.Crawler
+	var resumed client.Checkpoint
+	resuming := false
+	if *checkpoint != "" {
+		crawler = &client.Crawler{
+			Store: client.FileStore{Dir: *checkpoint},
+			OnDrift: func(d client.Drift) {
+				fmt.Fprintf(stderr, "pagefetch: page %d drifted, total %d -> %d, %d items skipped, %d items duplicated\n",
+					d.Page, d.PreviousTotal, d.Total, d.Skipped.Length, d.Duplicated.Length)
+			},
+		}
+
+		var err error
+		if resumed, resuming, err = crawler.Checkpoint(flags.Arg(0)); err != nil {
+			fmt.Fprintf(stderr, "pagefetch: %v\n", err)
+			return 1
+		}
+	}
+
+	out := stdout
+	if *output != "" {
+		// a resumed fetch appends to the items written before the interruption
+		mode := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if resuming {
+			mode = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		file, err := os.OpenFile(*output, mode, 0644)
+		if err != nil {
+			fmt.Fprintf(stderr, "pagefetch: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	buffered := bufio.NewWriter(out)
+	w := newWriter(buffered)
+	if cw, ok := w.(*csvWriter); ok && resuming {
+		cw.resume(*output)
+	}
+
+	fetcher := &client.Fetcher{
+		Client:      &http.Client{Timeout: *timeout},
+		Concurrency: *concurrency,
+		Interval:    *interval,
+		Retries:     *retries,
+		Backoff:     *backoff,
+		NewRequest: func(ctx context.Context, link string) (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodGet, link, nil)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range header {
+				colon := strings.IndexByte(h, ':')
+				req.Header.Add(strings.TrimSpace(h[:colon]), strings.TrimSpace(h[colon+1:]))
+			}
+			return req, nil
+		},
+	}
+
+	progress := &progress{w: stderr, quiet: *quiet, pages: resumed.Pages, items: resumed.Items}
+	visit := func(page client.Page) error {
+		if page.Items == nil && len(bytes.TrimSpace(page.Body)) > 0 {
+			return fmt.Errorf("no items found in the response of %s", page.URL)
+		}
+		for _, item := range page.Items {
+			if err := w.write(item); err != nil {
+				return err
+			}
+		}
+		// the items reach the output before the checkpoint of the page is saved
+		if err := w.flush(); err != nil {
+			return err
+		}
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		progress.report(page)
+		return nil
+	}
+
+	var err error
+	if crawler != nil {
+		crawler.Fetcher = fetcher
+		err = crawler.Crawl(ctx, flags.Arg(0), visit)
+	} else {
+		err = fetcher.FetchAll(ctx, flags.Arg(0), visit)
+	}
+
+	if closeErr := w.close(); err == nil {
+		err = closeErr
+	}
+	if flushErr := buffered.Flush(); err == nil {
+		err = flushErr
+	}
+	progress.done()
+
+	if err != nil {
+		fmt.Fprintf(stderr, "pagefetch: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// progress reports the fetched pages and items against the last page and the total
+type progress struct {
+	w     io.Writer
+	quiet bool
+	pages int64
+	items int64
+}
+
+func (p *progress) report(page client.Page) {
+	p.pages++
+	p.items += int64(len(page.Items))
+	if p.quiet {
+		return
+	}
+
+	line := fmt.Sprintf("page %d", p.pages)
+	if page.Last > 0 {
+		line += fmt.Sprintf("/%d", page.Last-page.First+1)
+	}
+	line += fmt.Sprintf(", %d", p.items)
+	if page.HasTotal {
+		line += fmt.Sprintf("/%d", page.Total)
+		if page.Total > 0 {
+			line += fmt.Sprintf(" items (%d%%)", 100*p.items/page.Total)
+		} else {
+			line += " items"
+		}
+	} else {
+		line += " items"
+	}
+
+	fmt.Fprintf(p.w, "\r%s", line)
+}
+
+func (p *progress) done() {
+	if !p.quiet && p.pages > 0 {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// itemWriter writes the items in an output format, flush passes the written items to the underlying writer
+type itemWriter interface {
+	write(item json.RawMessage) error
+	flush() error
+	close() error
+}
+
+var writers = map[string]func(w io.Writer) itemWriter{
+	"ndjson": func(w io.Writer) itemWriter { return &ndjsonWriter{w: w} },
+	"json":   func(w io.Writer) itemWriter { return &arrayWriter{w: w} },
+	"csv":    func(w io.Writer) itemWriter { return &csvWriter{w: csv.NewWriter(w)} },
+}
+
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (w *ndjsonWriter) write(item json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, item); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.w.Write(buf.Bytes())
+	return err
+}
+
+func (w *ndjsonWriter) flush() error {
+	return nil
+}
+
+func (w *ndjsonWriter) close() error {
+	return nil
+}
+
+type arrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *arrayWriter) write(item json.RawMessage) error {
+	var buf bytes.Buffer
+	if w.count == 0 {
+		buf.WriteString("[\n")
+	} else {
+		buf.WriteString(",\n")
+	}
+	if err := json.Compact(&buf, item); err != nil {
+		return err
+	}
+	w.count++
+
+	_, err := w.w.Write(buf.Bytes())
+	return err
+}
+
+func (w *arrayWriter) flush() error {
+	return nil
+}
+
+func (w *arrayWriter) close() error {
+	closing := "\n]\n"
+	if w.count == 0 {
+		closing = "[]\n"
+	}
+
+	_, err := io.WriteString(w.w, closing)
+	return err
+}
+
+// csvWriter writes the items as CSV records, the columns are the keys of the first item in their order
+type csvWriter struct {
+	w          *csv.Writer
+	columns    []string
+	skipHeader bool
+}
+
+// resume continues the records of an interrupted fetch without writing the header again,
+// the columns are read from the header of the output file, or from the first item if it can't be read
+func (w *csvWriter) resume(path string) {
+	w.skipHeader = true
+
+	if path == "" {
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if header, err := csv.NewReader(file).Read(); err == nil {
+		w.columns = header
+	}
+}
+
+func (w *csvWriter) write(item json.RawMessage) error {
+	keys, values, err := decodeObject(item)
+	if err != nil {
+		return err
+	}
+
+	if w.columns == nil {
+		w.columns = keys
+		if !w.skipHeader {
+			if err := w.w.Write(keys); err != nil {
+				return err
+			}
+		}
+	}
+
+	record := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		record[i] = csvValue(values[column])
+	}
+
+	return w.w.Write(record)
+}
+
+func (w *csvWriter) flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) close() error {
+	return w.flush()
+}
+
+// decodeObject returns the keys of the JSON object in order and the values by the keys
+func decodeObject(data json.RawMessage) (keys []string, values map[string]json.RawMessage, err error) {
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, nil, errors.New("csv format requires the items are JSON objects")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, token.(string))
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return keys, values, nil
+}
+
+// csvValue formats a JSON value as a CSV field, the strings are unquoted, the objects and the arrays are kept as JSON
+func csvValue(value json.RawMessage) string {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || string(value) == "null" {
+		return ""
+	}
+
+	var s string
+	if value[0] == '"' && json.Unmarshal(value, &s) == nil {
+		return s
+	}
+
+	return string(value)
+}
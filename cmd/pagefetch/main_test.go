@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+type Book struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Tags   []string `json:"tags"`
+	Author *string  `json:"author"`
+}
+
+type Books []Book
+
+func (b Books) Len() int                                    { return len(b) }
+func (b Books) Slice(start, end int) pagination.Truncatable { return b[start:end] }
+
+// newServer serves 5 books, the first request of each page fails with 503 if flaky is true
+func newServer(links bool, flaky bool) *httptest.Server {
+	return httptest.NewServer(newHandler(links, flaky))
+}
+
+func newHandler(links bool, flaky bool) http.Handler {
+	jk := "jk"
+	books := Books{
+		{0, "a", []string{"x"}, &jk},
+		{1, "b, c", nil, nil},
+		{2, `say "hi"`, []string{}, &jk},
+		{3, "d", nil, nil},
+		{4, "e", nil, nil},
+	}
+	pg := pagination.DefaultPagination()
+
+	var mu sync.Mutex
+	failed := map[string]bool{}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		fail := flaky && !failed[r.URL.String()]
+		failed[r.URL.String()] = true
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		paginated := pg.Parse(r.URL.String()).WrapWithTruncate(books, int64(len(books)))
+		if links {
+			w.Header().Set("X-Total-Count", fmt.Sprint(len(books)))
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, paginated.Pagination.Next, paginated.Pagination.Last))
+			json.NewEncoder(w).Encode(paginated.Result)
+			return
+		}
+		json.NewEncoder(w).Encode(paginated)
+	})
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		testName string
+		args     []string
+		links    bool
+		flaky    bool
+		code     int
+		stdout   string
+		stderr   string
+	}{
+		{
+			"ndjson",
+			[]string{"-format", "ndjson"},
+			false, false, 0,
+			`{"id":0,"name":"a","tags":["x"],"author":"jk"}
+{"id":1,"name":"b, c","tags":null,"author":null}
+{"id":2,"name":"say \"hi\"","tags":[],"author":"jk"}
+{"id":3,"name":"d","tags":null,"author":null}
+{"id":4,"name":"e","tags":null,"author":null}
+`,
+			"page 3/3, 5/5 items (100%)\n",
+		},
+		{
+			"csv from link headers",
+			[]string{"-format", "csv", "-concurrency", "1"},
+			true, false, 0,
+			`id,name,tags,author
+0,a,"[""x""]",jk
+1,"b, c",,
+2,"say ""hi""",[],jk
+3,d,,
+4,e,,
+`,
+			"page 3/3, 5/5 items (100%)\n",
+		},
+		{
+			"json array with retries",
+			[]string{"-format", "json", "-retries", "1", "-backoff", "1ms", "-quiet"},
+			false, true, 0,
+			`[
+{"id":0,"name":"a","tags":["x"],"author":"jk"},
+{"id":1,"name":"b, c","tags":null,"author":null},
+{"id":2,"name":"say \"hi\"","tags":[],"author":"jk"},
+{"id":3,"name":"d","tags":null,"author":null},
+{"id":4,"name":"e","tags":null,"author":null}
+]
+`,
+			"",
+		},
+		{
+			"no retries",
+			[]string{"-retries", "0", "-quiet"},
+			false, true, 1,
+			"",
+			"503 Service Unavailable",
+		},
+		{
+			"unknown format",
+			[]string{"-format", "xml"},
+			false, false, 2,
+			"",
+			`unknown format "xml"`,
+		},
+	}
+
+	for i, test := range tests {
+		server := newServer(test.links, test.flaky)
+
+		var stdout, stderr bytes.Buffer
+		args := append(test.args, "-H", "Authorization: Bearer token", server.URL+"/books?page_size=2")
+		code := run(context.Background(), args, &stdout, &stderr)
+		server.Close()
+
+		if code != test.code {
+			t.Errorf("%d. [%s] exit code: got %d, want %d (%s)", i, test.testName, code, test.code, stderr.String())
+		}
+		if stdout.String() != test.stdout {
+			t.Errorf("%d. [%s] stdout:\ngot\n%s\nwant\n%s", i, test.testName, stdout.String(), test.stdout)
+		}
+		if progress := stderr.String(); !strings.Contains(progress, test.stderr) {
+			t.Errorf("%d. [%s] stderr: got %q, want it contains %q", i, test.testName, progress, test.stderr)
+		}
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pagefetch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		testName string
+		format   string
+		want     string
+	}{
+		{
+			"ndjson",
+			"ndjson",
+			`{"id":0,"name":"a","tags":["x"],"author":"jk"}
+{"id":1,"name":"b, c","tags":null,"author":null}
+{"id":2,"name":"say \"hi\"","tags":[],"author":"jk"}
+{"id":3,"name":"d","tags":null,"author":null}
+{"id":4,"name":"e","tags":null,"author":null}
+`,
+		},
+		{
+			"csv",
+			"csv",
+			`id,name,tags,author
+0,a,"[""x""]",jk
+1,"b, c",,
+2,"say ""hi""",[],jk
+3,d,,
+4,e,,
+`,
+		},
+	}
+
+	for i, test := range tests {
+		// the third page is down until the crawl is resumed
+		var down int32 = 1
+		handler := newHandler(false, false)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&down) == 1 && r.URL.Query().Get("page") == "3" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		}))
+
+		output := filepath.Join(dir, test.testName)
+		args := []string{
+			"-format", test.format, "-concurrency", "1", "-retries", "0", "-checkpoint", filepath.Join(dir, "checkpoints"), "-o", output,
+			"-H", "Authorization: Bearer token", server.URL + "/books?page_size=2",
+		}
+
+		var stderr bytes.Buffer
+		if code := run(context.Background(), args, ioutil.Discard, &stderr); code != 1 {
+			t.Errorf("%d. [%s] crashed crawl: got exit code %d, want 1 (%s)", i, test.testName, code, stderr.String())
+		}
+
+		atomic.StoreInt32(&down, 0)
+		stderr.Reset()
+		if code := run(context.Background(), args, ioutil.Discard, &stderr); code != 0 {
+			t.Errorf("%d. [%s] resumed crawl: got exit code %d (%s)", i, test.testName, code, stderr.String())
+		}
+		server.Close()
+
+		if got, _ := ioutil.ReadFile(output); string(got) != test.want {
+			t.Errorf("%d. [%s] output:\ngot\n%s\nwant\n%s", i, test.testName, got, test.want)
+		}
+		if progress := stderr.String(); !strings.Contains(progress, "page 3/3, 5/5 items (100%)") {
+			t.Errorf("%d. [%s] resumed progress: got %q, want it counts the pages before the crash", i, test.testName, progress)
+		}
+	}
+
+	var stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-format", "json", "-checkpoint", dir, "http://example.com"}, ioutil.Discard, &stderr); code != 2 {
+		t.Errorf("json with a checkpoint: got exit code %d, want 2 (%s)", code, stderr.String())
+	}
+}
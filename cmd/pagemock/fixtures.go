@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// record is an item of a fixture, raw is responded as it is and values feed the sorting and filtering
+type record struct {
+	raw    json.RawMessage
+	values map[string]interface{}
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	return r.raw, nil
+}
+
+// loadFixture loads a JSON array of objects, or a CSV file whose first row names the fields.
+// It returns the records and the field names in the order they appear.
+func loadFixture(path string) (records []record, fields []string, err error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return loadJSON(path)
+	case ".csv":
+		return loadCSV(path)
+	}
+
+	return nil, nil, fmt.Errorf("fixture %s: unsupported extension, want .json or .csv", path)
+}
+
+func loadJSON(path string) (records []record, fields []string, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, nil, fmt.Errorf("fixture %s: %v", path, err)
+	}
+
+	seen := map[string]bool{}
+	for i, raw := range raws {
+		keys, err := objectKeys(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fixture %s: item %d: %v", path, i, err)
+		}
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				fields = append(fields, key)
+			}
+		}
+
+		r := record{raw: raw}
+		if err := json.Unmarshal(raw, &r.values); err != nil {
+			return nil, nil, fmt.Errorf("fixture %s: item %d: %v", path, i, err)
+		}
+		records = append(records, r)
+	}
+
+	return records, fields, nil
+}
+
+// objectKeys returns the keys of the JSON object in order
+func objectKeys(raw json.RawMessage) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// loadCSV loads the rows as objects, the numbers and booleans are typed and the empty cells are null
+func loadCSV(path string) (records []record, fields []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("fixture %s: %v", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("fixture %s: missing the header row", path)
+	}
+
+	fields = rows[0]
+	for _, row := range rows[1:] {
+		r := record{values: make(map[string]interface{}, len(fields))}
+
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i, field := range fields {
+			var value interface{}
+			if i < len(row) {
+				value = csvValue(row[i])
+			}
+			r.values[field] = value
+
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(field)
+			encoded, _ := json.Marshal(value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(encoded)
+		}
+		buf.WriteByte('}')
+
+		r.raw = buf.Bytes()
+		records = append(records, r)
+	}
+
+	return records, fields, nil
+}
+
+func csvValue(cell string) interface{} {
+	if cell == "" {
+		return nil
+	}
+	if n, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(cell, 64); err == nil {
+		return f
+	}
+	if cell == "true" || cell == "false" {
+		return cell == "true"
+	}
+
+	return cell
+}
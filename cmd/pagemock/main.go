@@ -0,0 +1,124 @@
+// Command pagemock serves JSON or CSV fixtures as paginated collections, the way the services using this library do.
+//
+// Each collection is served at a path, and it is paginated, sorted and filtered by the profile routed from the path
+// in the registry file, see pagination.Registry, so the parameter names and the envelopes are the same.
+// The sorting and the filtering of any field are enabled if the profile doesn't configure them:
+//
+//	pagemock -collection /books=fixtures/books.json -collection /authors=fixtures/authors.csv -config pagination.json
+//
+// The latency, the errors and the total drift between requests are injected to exercise the clients:
+//
+//	pagemock -collection /books=fixtures/books.json -latency 200ms -jitter 300ms -error-rate 0.1 -drift 2
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zheeeng/pagination"
+)
+
+// collectionFlags collects the repeated -collection flags
+type collectionFlags []string
+
+func (c *collectionFlags) String() string {
+	return strings.Join(*c, ", ")
+}
+
+func (c *collectionFlags) Set(value string) error {
+	eq := strings.IndexByte(value, '=')
+	if eq <= 0 || eq == len(value)-1 || !strings.HasPrefix(value, "/") {
+		return fmt.Errorf("collection %q must be like \"/path=fixture.json\"", value)
+	}
+	*c = append(*c, value)
+	return nil
+}
+
+func main() {
+	handler, listen, err := newServer(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	log.Printf("pagemock: listening on %s", listen)
+	log.Fatal(http.ListenAndServe(listen, handler))
+}
+
+// newServer returns the server configured by the arguments and the listen address, the errors are reported to stderr
+func newServer(args []string, stderr io.Writer) (s *server, listen string, err error) {
+	flags := flag.NewFlagSet("pagemock", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var collections collectionFlags
+	s = &server{}
+	flags.StringVar(&listen, "listen", ":8080", "listen address")
+	flags.Var(&collections, "collection", "collection served at a path like \"/books=books.json\", repeatable")
+	config := flags.String("config", "", "registry file of the pagination profiles routed by the collection paths")
+	flags.DurationVar(&s.latency, "latency", 0, "delay of each response")
+	flags.DurationVar(&s.jitter, "jitter", 0, "maximum random delay added to the latency")
+	flags.Float64Var(&s.errorRate, "error-rate", 0, "ratio of the requests responded with the error status, from 0 to 1")
+	flags.IntVar(&s.errorStatus, "error-status", http.StatusInternalServerError, "status of the injected errors")
+	flags.IntVar(&s.drift, "drift", 0, "items added, or removed if negative, at the front of a collection after each request")
+	seed := flags.Int64("seed", 0, "seed of the injected jitter and errors, the current time by default")
+
+	fail := func(err error) (*server, string, error) {
+		fmt.Fprintf(stderr, "pagemock: %v\n", err)
+		return nil, "", err
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if len(collections) == 0 {
+		return fail(fmt.Errorf("at least one -collection is required"))
+	}
+	if s.errorRate < 0 || s.errorRate > 1 {
+		return fail(fmt.Errorf("-error-rate %v must be from 0 to 1", s.errorRate))
+	}
+	if http.StatusText(s.errorStatus) == "" {
+		return fail(fmt.Errorf("-error-status %d is unknown", s.errorStatus))
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	s.rand = rand.New(rand.NewSource(*seed))
+
+	registry := pagination.NewRegistry()
+	if *config != "" {
+		if err := registry.LoadFile(*config); err != nil {
+			return fail(err)
+		}
+	}
+
+	s.collections = map[string]*collection{}
+	for _, c := range collections {
+		eq := strings.IndexByte(c, '=')
+		path, fixture := strings.TrimSuffix(c[:eq], "/"), c[eq+1:]
+
+		records, fields, err := loadFixture(fixture)
+		if err != nil {
+			return fail(err)
+		}
+
+		profile, _, ok := registry.Lookup(path)
+		if !ok {
+			profile = pagination.DefaultProfile
+		}
+		cfg, _ := registry.Configuration(profile)
+
+		s.collections[path] = newCollection(records, fields, cfg)
+	}
+
+	return s, listen, nil
+}
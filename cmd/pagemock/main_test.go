@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zheeeng/pagination"
+	"github.com/zheeeng/pagination/proxy"
+)
+
+const booksFixture = `[
+	{"id":1,"title":"a","year":1999},
+	{"id":2,"title":"b","year":2005},
+	{"id":3,"title":"c","year":2001},
+	{"id":4,"title":"d","year":1990},
+	{"id":5,"title":"e","year":2010}
+]`
+
+const authorsFixture = `id,name,active
+1,jk,true
+2,"tolkien, j",false
+3,,true
+`
+
+const registryConfiguration = `{
+	"profiles": {
+		"default": {"page_size": 2},
+		"books": {"page_size": 2, "page_param": "p", "envelope": "drf"}
+	},
+	"routes": [{"pattern": "/books", "profile": "books"}]
+}`
+
+func writeFixtures(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pagemock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{
+		"books.json":      booksFixture,
+		"authors.csv":     authorsFixture,
+		"pagination.json": registryConfiguration,
+		"broken.json":     `{"id": 1}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestServer(t *testing.T) {
+	dir := writeFixtures(t)
+	defer os.RemoveAll(dir)
+
+	var stderr bytes.Buffer
+	s, _, err := newServer([]string{
+		"-collection", "/books=" + filepath.Join(dir, "books.json"),
+		"-collection", "/authors/=" + filepath.Join(dir, "authors.csv"),
+		"-config", filepath.Join(dir, "pagination.json"),
+	}, &stderr)
+	if err != nil {
+		t.Fatalf("new server: %v (%s)", err, stderr.String())
+	}
+
+	tests := []struct {
+		testName string
+		target   string
+		status   int
+		body     string
+	}{
+		{"profile envelope", "/books?p=2", http.StatusOK, `{"count":5,"next":"/books?p=3\u0026page_size=2","previous":"/books?p=1\u0026page_size=2","results":[{"id":3,"title":"c","year":2001},{"id":4,"title":"d","year":1990}]}`},
+		{"sorted", "/books?sort=-year", http.StatusOK, `"results":[{"id":5,"title":"e","year":2010},{"id":2,"title":"b","year":2005}]`},
+		{"filtered", "/books?filter=year=gt=2000&sort=year&page_size=5", http.StatusOK, `"count":3,`},
+		{"brackets", "/books?year[lt]=2000", http.StatusOK, `"results":[{"id":1,"title":"a","year":1999},{"id":4,"title":"d","year":1990}]`},
+		{"unknown field", "/books?sort=price", http.StatusOK, `"count":5,`},
+		{"unknown filter field", "/books?filter=price==1", http.StatusBadRequest, `missing accessor for field \"price\"`},
+		{"csv fixture", "/authors/?page=2", http.StatusOK, `"result":[{"id":3,"name":null,"active":true}]`},
+		{"csv filtered", "/authors?filter=active==false", http.StatusOK, `"result":[{"id":2,"name":"tolkien, j","active":false}]`},
+		{"unknown collection", "/shops", http.StatusNotFound, `{"error":"no collection is served at /shops"}`},
+	}
+
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", test.target, nil))
+
+		if w.Code != test.status {
+			t.Errorf("%d. [%s] status: got %d, want %d", i, test.testName, w.Code, test.status)
+		}
+		if body := w.Body.String(); !strings.Contains(body, test.body) {
+			t.Errorf("%d. [%s] body:\ngot  %s\nwant it contains %s", i, test.testName, body, test.body)
+		}
+	}
+}
+
+func TestServerInjection(t *testing.T) {
+	dir := writeFixtures(t)
+	defer os.RemoveAll(dir)
+
+	books := "/books=" + filepath.Join(dir, "books.json")
+
+	tests := []struct {
+		testName string
+		args     []string
+		status   int
+		totals   []string
+		latency  time.Duration
+	}{
+		{"no injection", []string{}, http.StatusOK, []string{"5", "5", "5"}, 0},
+		{"errors", []string{"-error-rate", "1", "-error-status", "503"}, http.StatusServiceUnavailable, []string{"", "", ""}, 0},
+		{"growing total", []string{"-drift", "2"}, http.StatusOK, []string{"5", "7", "9"}, 0},
+		{"shrinking total", []string{"-drift", "-3"}, http.StatusOK, []string{"5", "2", "0"}, 0},
+		{"latency", []string{"-latency", "20ms", "-jitter", "10ms", "-seed", "1"}, http.StatusOK, []string{"5", "5", "5"}, 60 * time.Millisecond},
+	}
+
+	for i, test := range tests {
+		var stderr bytes.Buffer
+		s, _, err := newServer(append(test.args, "-collection", books), &stderr)
+		if err != nil {
+			t.Fatalf("%d. [%s] new server: %v (%s)", i, test.testName, err, stderr.String())
+		}
+
+		started := time.Now()
+		var totals []string
+		for range test.totals {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest("GET", "/books", nil))
+			if w.Code != test.status {
+				t.Errorf("%d. [%s] status: got %d, want %d", i, test.testName, w.Code, test.status)
+			}
+			totals = append(totals, w.Header().Get("X-Total-Count"))
+		}
+
+		if strings.Join(totals, ",") != strings.Join(test.totals, ",") {
+			t.Errorf("%d. [%s] totals: got %v, want %v", i, test.testName, totals, test.totals)
+		}
+		if elapsed := time.Since(started); elapsed < test.latency {
+			t.Errorf("%d. [%s] latency: got %v, want at least %v", i, test.testName, elapsed, test.latency)
+		}
+	}
+}
+
+func TestNewServerErrors(t *testing.T) {
+	dir := writeFixtures(t)
+	defer os.RemoveAll(dir)
+
+	for i, args := range [][]string{
+		{},
+		{"-collection", "books.json"},
+		{"-collection", "/books=" + filepath.Join(dir, "missing.json")},
+		{"-collection", "/books=" + filepath.Join(dir, "broken.json")},
+		{"-collection", "/books=" + filepath.Join(dir, "pagination.yaml")},
+		{"-collection", "/books=" + filepath.Join(dir, "books.json"), "-error-rate", "2"},
+		{"-collection", "/books=" + filepath.Join(dir, "books.json"), "-error-status", "999"},
+		{"-collection", "/books=" + filepath.Join(dir, "books.json"), "-config", filepath.Join(dir, "missing.json")},
+	} {
+		var stderr bytes.Buffer
+		if _, _, err := newServer(args, &stderr); err == nil || stderr.Len() == 0 {
+			t.Errorf("%d. %v: got %v, want a reported error", i, args, err)
+		}
+	}
+}
+
+func TestServerRangeAsProxy(t *testing.T) {
+	dir := writeFixtures(t)
+	defer os.RemoveAll(dir)
+
+	var stderr bytes.Buffer
+	s, _, err := newServer([]string{
+		"-collection", "/books=" + filepath.Join(dir, "books.json"),
+		"-config", filepath.Join(dir, "pagination.json"),
+	}, &stderr)
+	if err != nil {
+		t.Fatalf("new server: %v (%s)", err, stderr.String())
+	}
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(booksFixture))
+	}))
+	defer upstream.Close()
+
+	registry := pagination.NewRegistry()
+	if err := registry.LoadFile(filepath.Join(dir, "pagination.json")); err != nil {
+		t.Fatal(err)
+	}
+	books, _ := registry.Profile("books")
+	p, err := proxy.New(upstream.URL, books)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, rangeHdr := range []string{"items=0-1", "items=2-3", "items=4-", "items=3-7", "items=-5", "items=a-b", "items=8-9", "pages=0-1"} {
+		mock, proxied := httptest.NewRecorder(), httptest.NewRecorder()
+		for _, served := range []struct {
+			h http.Handler
+			w *httptest.ResponseRecorder
+		}{{s, mock}, {p, proxied}} {
+			req := httptest.NewRequest("GET", "/books", nil)
+			req.Header.Set("Range", rangeHdr)
+			served.h.ServeHTTP(served.w, req)
+		}
+
+		if mock.Code != proxied.Code {
+			t.Errorf("%d. [%s] status: got %d, pageproxy got %d", i, rangeHdr, mock.Code, proxied.Code)
+		}
+		for _, name := range []string{"Content-Range", "Link", "X-Total-Count"} {
+			if got, want := mock.Header().Get(name), proxied.Header().Get(name); got != want {
+				t.Errorf("%d. [%s] %s: got %q, pageproxy got %q", i, rangeHdr, name, got, want)
+			}
+		}
+		if mock.Code < 300 && mock.Body.String() != proxied.Body.String() {
+			t.Errorf("%d. [%s] body:\ngot       %s\npageproxy %s", i, rangeHdr, mock.Body, proxied.Body)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zheeeng/pagination"
+)
+
+// collection is a fixture served at a path
+type collection struct {
+	pg        pagination.Pagination
+	accessors pagination.Accessors
+
+	mu      sync.Mutex
+	fixture []record
+	records []record
+	drifted int
+}
+
+// newCollection serves the fixture with the configuration, the sorting and filtering of the fixture fields are enabled if they aren't configured
+func newCollection(fixture []record, fields []string, cfg pagination.PaginatorConfiguration) *collection {
+	if cfg.Sort == nil {
+		cfg.Sort = &pagination.SortConfiguration{Fields: fields}
+	}
+	if cfg.Filter == nil {
+		cfg.Filter = &pagination.FilterConfiguration{Brackets: true}
+	}
+
+	accessors := make(pagination.Accessors, len(fields))
+	for _, field := range fields {
+		field := field
+		accessors[field] = func(item interface{}) interface{} {
+			return item.(record).values[field]
+		}
+	}
+
+	return &collection{
+		pg:        pagination.NewPagination(cfg),
+		accessors: accessors,
+		fixture:   fixture,
+		records:   fixture,
+	}
+}
+
+// snapshot returns the records to serve, and then drifts the total by adding or removing n items at the front.
+// The added items are copies of the fixture items.
+func (c *collection) snapshot(n int) []record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	records := c.records
+
+	switch {
+	case n > 0 && len(c.fixture) > 0:
+		added := make([]record, n, n+len(c.records))
+		for i := range added {
+			added[i] = c.fixture[(c.drifted+i)%len(c.fixture)]
+		}
+		c.drifted += n
+		c.records = append(added, c.records...)
+	case n < 0:
+		if -n > len(c.records) {
+			n = -len(c.records)
+		}
+		c.records = c.records[-n:]
+	}
+
+	return records
+}
+
+// server serves the collections and injects the latency, errors and total drift. By default no fault is injected:
+//
+// -- latency and jitter: each response is delayed by the latency plus a random duration up to the jitter
+//
+// -- errorRate and errorStatus: the ratio of requests responded with the error status
+//
+// -- drift: the number of items added (or removed if it is negative) at the front of a collection after each request
+type server struct {
+	collections map[string]*collection
+
+	latency     time.Duration
+	jitter      time.Duration
+	errorRate   float64
+	errorStatus int
+	drift       int
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (s *server) random() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rand.Float64()
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.collections[strings.TrimSuffix(r.URL.Path, "/")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "no collection is served at "+r.URL.Path)
+		return
+	}
+
+	delay := s.latency
+	if s.jitter > 0 {
+		delay += time.Duration(s.random() * float64(s.jitter))
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	if s.errorRate > 0 && s.random() < s.errorRate {
+		writeError(w, s.errorStatus, "injected error")
+		return
+	}
+
+	pgt := c.pg.Parse(r.URL.String())
+	rangeErr := pgt.ParseRange(r.Header.Get("Range"))
+
+	total, _, paginated, err := pgt.WrapSlice(c.snapshot(s.drift), c.accessors)
+
+	// a Range header which can't be served as a page is answered by 416, as pageproxy does
+	if rangeErr != nil {
+		if contentRange, status := pgt.ContentRange(total); status == http.StatusRequestedRangeNotSatisfiable {
+			w.Header().Set("Content-Range", contentRange)
+		}
+		writeError(w, http.StatusRequestedRangeNotSatisfiable, rangeErr.Error())
+		return
+	}
+
+	switch e := err.(type) {
+	case nil:
+	case *pagination.PageRedirectError:
+		http.Redirect(w, r, e.Location, http.StatusFound)
+		return
+	case *pagination.PageNotFoundError:
+		writeError(w, http.StatusNotFound, e.Error())
+		return
+	default:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body, err := json.Marshal(paginated)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	paginated.Pagination.WriteHeaders(w.Header())
+
+	if pgt.HasRawRange() {
+		if pgt.WriteRangeHeaders(w, total) == http.StatusRequestedRangeNotSatisfiable {
+			return
+		}
+	}
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
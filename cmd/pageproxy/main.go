@@ -0,0 +1,94 @@
+// Command pageproxy is a reverse proxy which paginates the upstream endpoints responding whole JSON arrays.
+//
+// The pages are responded in the pagination envelope with the Link and X-Total-Count headers:
+//
+//	pageproxy -upstream http://legacy.internal:8080/api -page-size 50 -max-page-size 200 -cache 30s
+//
+// The pagination profiles of a registry file, see pagination.Registry, are applied by the request paths:
+//
+//	pageproxy -upstream http://legacy.internal:8080/api -config pagination.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/zheeeng/pagination"
+	"github.com/zheeeng/pagination/proxy"
+)
+
+func main() {
+	handler, listen, err := newHandler(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	log.Printf("pageproxy: listening on %s", listen)
+	log.Fatal(http.ListenAndServe(listen, handler))
+}
+
+// newHandler returns the proxy configured by the arguments and the listen address, the errors are reported to stderr
+func newHandler(args []string, stderr io.Writer) (handler *proxy.Handler, listen string, err error) {
+	flags := flag.NewFlagSet("pageproxy", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var cfg pagination.PaginatorConfiguration
+	var envelope, outOfRange string
+	flags.StringVar(&listen, "listen", ":8080", "listen address")
+	upstream := flags.String("upstream", "", "upstream base URL, e.g. http://legacy.internal:8080/api")
+	config := flags.String("config", "", "registry file of the pagination profiles, it overrides the pagination flags")
+	cache := flags.Duration("cache", 0, "how long an upstream array is reused for the page requests, 0 disables the cache")
+	flags.Int64Var(&cfg.PageSize, "page-size", 30, "default page size")
+	flags.Int64Var(&cfg.MaxPageSize, "max-page-size", 0, "maximum page size, 0 means unlimited")
+	flags.StringVar(&cfg.PageParam, "page-param", "page", "page parameter name")
+	flags.StringVar(&cfg.PageSizeParam, "page-size-param", "page_size", "page size parameter name")
+	flags.StringVar(&envelope, "envelope", "", "response envelope: drf, spring or laravel, the pagination envelope by default")
+	flags.StringVar(&outOfRange, "out-of-range", "empty", "policy of a page beyond the last one: empty, clamp, redirect or not_found")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if *upstream == "" {
+		fmt.Fprintln(stderr, "pageproxy: -upstream is required")
+		return nil, "", fmt.Errorf("missing upstream")
+	}
+	cfg.Envelope = pagination.Envelope(envelope)
+	if err := cfg.OutOfRange.UnmarshalText([]byte(outOfRange)); err != nil {
+		fmt.Fprintf(stderr, "pageproxy: %v\n", err)
+		return nil, "", err
+	}
+
+	var pg pagination.Pagination
+	if *config != "" {
+		registry := pagination.NewRegistry()
+		if err := registry.LoadFile(*config); err != nil {
+			fmt.Fprintf(stderr, "pageproxy: %v\n", err)
+			return nil, "", err
+		}
+		pg = registry
+	} else {
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(stderr, "pageproxy: %v\n", err)
+			return nil, "", err
+		}
+		pg = pagination.NewPagination(cfg)
+	}
+
+	handler, err = proxy.New(*upstream, pg)
+	if err != nil {
+		fmt.Fprintf(stderr, "pageproxy: %v\n", err)
+		return nil, "", err
+	}
+	handler.CacheTTL = *cache
+	handler.Client = &http.Client{Timeout: time.Minute}
+
+	return handler, listen, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[1,2,3,4,5,6,7]`))
+	}))
+	defer upstream.Close()
+
+	dir, err := ioutil.TempDir("", "pageproxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := filepath.Join(dir, "pagination.json")
+	if err := ioutil.WriteFile(config, []byte(`{"profiles": {"default": {"page_size": 3, "envelope": "drf"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		testName string
+		args     []string
+		target   string
+		hasErr   bool
+		body     string
+	}{
+		{"flags", []string{"-page-size", "2", "-page-param", "p", "-upstream", upstream.URL}, "/numbers?p=2&page=2", false, `"result":[3,4]`},
+		{"registry file", []string{"-config", config, "-upstream", upstream.URL}, "/numbers?p=2&page=2", false, `"results":[4,5,6]`},
+		{"clamp", []string{"-page-size", "2", "-out-of-range", "clamp", "-upstream", upstream.URL, "-page-param", "p"}, "/numbers?p=9", false, `"result":[7]`},
+		{"missing upstream", []string{"-page-size", "2"}, "/numbers?p=2&page=2", true, ""},
+		{"unknown policy", []string{"-out-of-range", "wrap", "-upstream", upstream.URL}, "/numbers?p=2&page=2", true, ""},
+		{"invalid configuration", []string{"-page-param", "page_size", "-upstream", upstream.URL}, "/numbers?p=2&page=2", true, ""},
+		{"missing registry file", []string{"-config", filepath.Join(dir, "missing.json"), "-upstream", upstream.URL}, "/numbers?p=2&page=2", true, ""},
+	}
+
+	for i, test := range tests {
+		var stderr bytes.Buffer
+		handler, listen, err := newHandler(test.args, &stderr)
+		if (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+			continue
+		}
+		if err != nil {
+			if stderr.Len() == 0 {
+				t.Errorf("%d. [%s] expects the error is reported", i, test.testName)
+			}
+			continue
+		}
+		if listen != ":8080" {
+			t.Errorf("%d. [%s] listen: got %s", i, test.testName, listen)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", test.target, nil))
+		if body := w.Body.String(); !strings.Contains(body, test.body) {
+			t.Errorf("%d. [%s] body: got %s, want it contains %s", i, test.testName, body, test.body)
+		}
+	}
+}
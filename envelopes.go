@@ -0,0 +1,251 @@
+package pagination
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Envelope defines the shape of the paginated response body
+type Envelope string
+
+// Envelopes
+const (
+	// EnvelopeDefault is the Paginated shape: {pagination, result}
+	EnvelopeDefault Envelope = ""
+	// EnvelopeDRF is the Django REST Framework shape: {count, next, previous, results}
+	EnvelopeDRF Envelope = "drf"
+	// EnvelopeSpring is the Spring Data shape: {content, totalElements, totalPages, number, size, ...}
+	EnvelopeSpring Envelope = "spring"
+	// EnvelopeLaravel is the Laravel shape: {current_page, data, from, to, last_page, per_page, links, ...}
+	EnvelopeLaravel Envelope = "laravel"
+)
+
+// DRFPage defines the Django REST Framework PageNumberPagination response struct
+type DRFPage struct {
+	Count    int64       `json:"count"`
+	Next     *string     `json:"next"`
+	Previous *string     `json:"previous"`
+	Results  Truncatable `json:"results"`
+}
+
+// SpringPage defines the Spring Data Page response struct, Number is zero-based
+type SpringPage struct {
+	Content          Truncatable `json:"content"`
+	TotalElements    int64       `json:"totalElements"`
+	TotalPages       int64       `json:"totalPages"`
+	Number           int64       `json:"number"`
+	Size             int64       `json:"size"`
+	NumberOfElements int         `json:"numberOfElements"`
+	First            bool        `json:"first"`
+	Last             bool        `json:"last"`
+	Empty            bool        `json:"empty"`
+}
+
+// LaravelLink defines an item of the Laravel paginator links
+type LaravelLink struct {
+	URL    *string `json:"url"`
+	Label  string  `json:"label"`
+	Active bool    `json:"active"`
+}
+
+// LaravelPage defines the Laravel LengthAwarePaginator response struct
+type LaravelPage struct {
+	CurrentPage  int64         `json:"current_page"`
+	Data         Truncatable   `json:"data"`
+	FirstPageURL string        `json:"first_page_url"`
+	From         *int64        `json:"from"`
+	LastPage     int64         `json:"last_page"`
+	LastPageURL  string        `json:"last_page_url"`
+	Links        []LaravelLink `json:"links"`
+	NextPageURL  *string       `json:"next_page_url"`
+	Path         string        `json:"path"`
+	PerPage      int64         `json:"per_page"`
+	PrevPageURL  *string       `json:"prev_page_url"`
+	To           *int64        `json:"to"`
+	Total        int64         `json:"total"`
+}
+
+const laravelOnEachSide = 3
+
+func optionalString(s string, present bool) *string {
+	if !present {
+		return nil
+	}
+
+	return &s
+}
+
+func (p Paginated) count() int {
+	if p.Result == nil {
+		return 0
+	}
+
+	return p.Result.Len()
+}
+
+// fields returns the pagination fields, they are zero if the Paginated isn't built by a Paginator
+func (p Paginated) fields() *PageFields {
+	if p.Pagination == nil {
+		return &PageFields{}
+	}
+
+	return p.Pagination
+}
+
+// pageURL returns the link of the one-based page n of the last page, it is nil if it is unknown.
+// The fields built by hand or decoded from JSON only know the links they hold.
+func (f *PageFields) pageURL(n, last int64) *string {
+	if f.pageLink != nil {
+		link := f.pageLink(f.base + n - 1)
+		return &link
+	}
+
+	var link string
+	switch current := f.oneBasedPage(); {
+	case n == current-1:
+		link = f.Prev
+	case n == current+1:
+		link = f.Next
+	case n == 1:
+		link = f.First
+	case n == last:
+		link = f.Last
+	}
+
+	return optionalString(link, link != "")
+}
+
+// oneBasedPage returns the current page numbered from 1 regardless of the configured page base
+func (f *PageFields) oneBasedPage() int64 {
+	return f.Page - f.base + 1
+}
+
+// hasPrev and hasNext treat the unknown total as there being more pages
+func (f *PageFields) hasPrev() bool {
+	return f.oneBasedPage() > 1
+}
+
+func (f *PageFields) hasNext() bool {
+	return f.pages == 0 || f.oneBasedPage() < f.pages
+}
+
+// Render returns the paginated result in the shape of the envelope
+func (p Paginated) Render(envelope Envelope) interface{} {
+	switch envelope {
+	case EnvelopeDRF:
+		return p.DRF()
+	case EnvelopeSpring:
+		return p.Spring()
+	case EnvelopeLaravel:
+		return p.Laravel()
+	}
+
+	return p
+}
+
+// DRF returns the paginated result in the Django REST Framework shape
+func (p Paginated) DRF() DRFPage {
+	fields := p.fields()
+
+	return DRFPage{
+		Count:    fields.Total,
+		Next:     optionalString(fields.Next, fields.hasNext()),
+		Previous: optionalString(fields.Prev, fields.hasPrev()),
+		Results:  p.Result,
+	}
+}
+
+// Spring returns the paginated result in the Spring Data shape
+func (p Paginated) Spring() SpringPage {
+	fields := p.fields()
+	count := p.count()
+
+	return SpringPage{
+		Content:          p.Result,
+		TotalElements:    fields.Total,
+		TotalPages:       fields.pages,
+		Number:           fields.oneBasedPage() - 1,
+		Size:             fields.PageSize,
+		NumberOfElements: count,
+		First:            !fields.hasPrev(),
+		Last:             !fields.hasNext(),
+		Empty:            count == 0,
+	}
+}
+
+// Laravel returns the paginated result in the Laravel shape,
+// the page numbers are one-based as Laravel does while the links keep the configured page base
+func (p Paginated) Laravel() LaravelPage {
+	fields := p.fields()
+	count := p.count()
+
+	lastPage := fields.pages
+	if lastPage == 0 && fields.PageSize > 0 {
+		lastPage = (fields.Total + fields.PageSize - 1) / fields.PageSize
+	}
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	page := LaravelPage{
+		CurrentPage:  fields.oneBasedPage(),
+		Data:         p.Result,
+		FirstPageURL: fields.First,
+		LastPage:     lastPage,
+		LastPageURL:  fields.Last,
+		NextPageURL:  optionalString(fields.Next, fields.hasNext()),
+		Path:         strings.SplitN(fields.First, "?", 2)[0],
+		PerPage:      fields.PageSize,
+		PrevPageURL:  optionalString(fields.Prev, fields.hasPrev()),
+		Total:        fields.Total,
+	}
+
+	if lastPageURL := fields.pageURL(lastPage, lastPage); lastPageURL != nil {
+		page.LastPageURL = *lastPageURL
+	}
+
+	if count > 0 {
+		from, to := fields.offset+1, fields.offset+int64(count)
+		page.From, page.To = &from, &to
+	}
+
+	page.Links = append(page.Links, LaravelLink{page.PrevPageURL, "&laquo; Previous", false})
+	for _, n := range laravelWindow(page.CurrentPage, lastPage) {
+		if n == 0 {
+			page.Links = append(page.Links, LaravelLink{nil, "...", false})
+			continue
+		}
+		page.Links = append(page.Links, LaravelLink{fields.pageURL(n, lastPage), strconv.FormatInt(n, 10), n == page.CurrentPage})
+	}
+	page.Links = append(page.Links, LaravelLink{page.NextPageURL, "Next &raquo;", false})
+
+	return page
+}
+
+// laravelWindow returns the page numbers shown in the Laravel links, 0 stands for the "..." separator
+func laravelWindow(current, last int64) []int64 {
+	pages := func(from, to int64) []int64 {
+		var numbers []int64
+		for n := from; n <= to; n++ {
+			numbers = append(numbers, n)
+		}
+		return numbers
+	}
+
+	if last < laravelOnEachSide*2+8 {
+		return pages(1, last)
+	}
+
+	window := int64(laravelOnEachSide + 4)
+	switch {
+	case current <= window:
+		return append(append(pages(1, window+laravelOnEachSide), 0), pages(last-1, last)...)
+	case current > last-window:
+		return append(append(pages(1, 2), 0), pages(last-(window+laravelOnEachSide-1), last)...)
+	}
+
+	numbers := append(pages(1, 2), 0)
+	numbers = append(numbers, pages(current-laravelOnEachSide, current+laravelOnEachSide)...)
+
+	return append(append(numbers, 0), pages(last-1, last)...)
+}
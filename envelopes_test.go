@@ -0,0 +1,140 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func render(t *testing.T, link string, envelope pagination.Envelope) map[string]interface{} {
+	paginated := pagination.DefaultPagination().Parse(link).WrapWithTruncate(TrunctableBooks(books), total)
+
+	encoded, err := json.Marshal(paginated.Render(envelope))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal(encoded, &body)
+
+	return body
+}
+
+func TestDRFEnvelope(t *testing.T) {
+	body := render(t, requestURI, pagination.EnvelopeDRF)
+
+	if body["count"] != 20.0 ||
+		body["next"] != "api.example.com/books?author=jk&page=3&page_size=5" ||
+		body["previous"] != "api.example.com/books?author=jk&page=1&page_size=5" ||
+		len(body["results"].([]interface{})) != 5 {
+		t.Errorf("[drf]: got %v", body)
+	}
+
+	body = render(t, "api.example.com/books?page=4&page_size=5", pagination.EnvelopeDRF)
+	if body["next"] != nil || body["previous"] == nil {
+		t.Errorf("[drf last page]: got %v", body)
+	}
+}
+
+func TestSpringEnvelope(t *testing.T) {
+	body := render(t, requestURI, pagination.EnvelopeSpring)
+
+	if body["totalElements"] != 20.0 || body["totalPages"] != 4.0 ||
+		body["number"] != 1.0 || body["size"] != 5.0 || body["numberOfElements"] != 5.0 ||
+		body["first"] != false || body["last"] != false || body["empty"] != false ||
+		len(body["content"].([]interface{})) != 5 {
+		t.Errorf("[spring]: got %v", body)
+	}
+
+	body = render(t, "api.example.com/books?page=1&page_size=5", pagination.EnvelopeSpring)
+	if body["number"] != 0.0 || body["first"] != true {
+		t.Errorf("[spring first page]: got %v", body)
+	}
+}
+
+func TestLaravelEnvelope(t *testing.T) {
+	body := render(t, requestURI, pagination.EnvelopeLaravel)
+
+	if body["current_page"] != 2.0 || body["last_page"] != 4.0 || body["per_page"] != 5.0 ||
+		body["from"] != 6.0 || body["to"] != 10.0 || body["total"] != 20.0 ||
+		body["path"] != "api.example.com/books" ||
+		body["last_page_url"] != "api.example.com/books?author=jk&page=4&page_size=5" ||
+		body["next_page_url"] != "api.example.com/books?author=jk&page=3&page_size=5" {
+		t.Errorf("[laravel]: got %v", body)
+	}
+
+	links := body["links"].([]interface{})
+	labels := []string{"&laquo; Previous", "1", "2", "3", "4", "Next &raquo;"}
+	if len(links) != len(labels) {
+		t.Fatalf("[laravel links]: got %v", links)
+	}
+	for i, label := range labels {
+		link := links[i].(map[string]interface{})
+		if link["label"] != label || link["active"] != (label == "2") {
+			t.Errorf("[laravel link %d]: got %v", i, link)
+		}
+	}
+
+	body = render(t, "api.example.com/books?page=9&page_size=5", pagination.EnvelopeLaravel)
+	if body["from"] != nil || body["to"] != nil || body["next_page_url"] != nil {
+		t.Errorf("[laravel out of range]: got %v", body)
+	}
+}
+
+func TestLaravelLinksWindow(t *testing.T) {
+	paginated := pagination.DefaultPagination().Parse("api.example.com/books?page=10&page_size=1").
+		Wrap(TrunctableBooks(books[:1]), 20)
+
+	var labels []string
+	for _, link := range paginated.Laravel().Links {
+		labels = append(labels, link.Label)
+	}
+
+	want := []string{"&laquo; Previous", "1", "2", "...", "7", "8", "9", "10", "11", "12", "13", "...", "19", "20", "Next &raquo;"}
+	if len(labels) != len(want) {
+		t.Fatalf("[laravel window]: got %v, want %v", labels, want)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Fatalf("[laravel window]: got %v, want %v", labels, want)
+		}
+	}
+}
+
+func TestEnvelopesOfUnbuiltFields(t *testing.T) {
+	tests := []struct {
+		testName  string
+		paginated pagination.Paginated
+		lastURL   string
+		links     int
+	}{
+		{"nil fields", pagination.Paginated{}, "", 3},
+		{"zero fields", pagination.Paginated{Pagination: &pagination.PageFields{}}, "", 3},
+		{
+			"decoded fields",
+			pagination.Paginated{Pagination: &pagination.PageFields{
+				PageSize: 5, Total: 12, First: "/books?page=0", Last: "/books?page=2", Next: "/books?page=1",
+			}},
+			"/books?page=2", 5,
+		},
+	}
+
+	for i, test := range tests {
+		for _, envelope := range []pagination.Envelope{pagination.EnvelopeDRF, pagination.EnvelopeSpring, pagination.EnvelopeLaravel} {
+			if _, err := json.Marshal(test.paginated.Render(envelope)); err != nil {
+				t.Errorf("%d. [%s] %s: %v", i, test.testName, envelope, err)
+			}
+		}
+
+		laravel := test.paginated.Laravel()
+		if laravel.LastPageURL != test.lastURL || len(laravel.Links) != test.links {
+			t.Errorf("%d. [%s] laravel: got last page url %q and %d links, want %q and %d links",
+				i, test.testName, laravel.LastPageURL, len(laravel.Links), test.lastURL, test.links)
+		}
+		if test.links == 5 && (laravel.Links[1].URL == nil || *laravel.Links[1].URL != "/books?page=0" ||
+			laravel.Links[2].URL == nil || *laravel.Links[2].URL != "/books?page=1") {
+			t.Errorf("%d. [%s] laravel links: got %+v", i, test.testName, laravel.Links)
+		}
+	}
+}
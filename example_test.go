@@ -22,7 +22,7 @@ func (tb TrunctableBooks) Len() int {
 	return len(tb)
 }
 
-var total = 20
+var total int64 = 20
 var requestURI = "api.example.com/books?author=jk&page=2&page_size=5"
 var books = []Book{}
 
@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/zheeeng/pagination/fieldsets"
+)
+
+// Objects is the Result of the wrapped items when a fieldset is selected or sub-collections are nested,
+// each item is held as the JSON object it is encoded to, and its fields keep the order of the item encoding
+type Objects []json.RawMessage
+
+// Len returns the number of the objects
+func (o Objects) Len() int {
+	return len(o)
+}
+
+// Slice returns the objects between the indexes
+func (o Objects) Slice(startIndex, endIndex int) Truncatable {
+	return o[startIndex:endIndex]
+}
+
+// MarshalJSON encodes the objects as a JSON array, it is empty rather than null if there is no object
+func (o Objects) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]json.RawMessage(o))
+}
+
+// project returns the items projected to the selected fieldset as Objects, they are kept as they are if no field is selected.
+// The items of a Slice kind Truncatable may implement fieldsets.Projector,
+// the others are projected from their JSON encoding.
+func (p *Paginator) project(items Truncatable) (Truncatable, error) {
+	if len(p.fieldset) == 0 {
+		return items, nil
+	}
+
+	if value, ok := sliceValue(items); ok {
+		projected := make(Objects, value.Len())
+		for i := range projected {
+			item, err := fieldsets.Project(value.Index(i).Interface(), p.fieldset)
+			if err != nil {
+				return items, err
+			}
+			projected[i] = item
+		}
+
+		return projected, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return items, err
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return items, nil
+	}
+
+	var projected Objects
+	if err := json.Unmarshal(data, &projected); err != nil {
+		return items, err
+	}
+	for i, item := range projected {
+		if projected[i], err = fieldsets.ProjectJSON(item, p.fieldset); err != nil {
+			return items, err
+		}
+	}
+
+	return projected, nil
+}
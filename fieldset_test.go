@@ -0,0 +1,100 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func TestFieldset(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		Fieldset: &pagination.FieldsetConfiguration{Fields: []string{"id", "name", "author"}},
+	})
+
+	tests := []struct {
+		testName string
+		link     string
+		next     string
+		result   string
+		hasErr   bool
+	}{
+		{
+			"selected fields",
+			"api.example.com/books?fields=name,id&page=2&page_size=2",
+			"api.example.com/books?fields=name%2Cid&page=3&page_size=2",
+			`[{"id":2,"name":"book"},{"id":3,"name":"book"}]`,
+			false,
+		},
+		{
+			"unselectable field",
+			"api.example.com/books?fields=id,+password&page_size=2",
+			"api.example.com/books?fields=id&page=2&page_size=2",
+			`[{"id":0},{"id":1}]`,
+			true,
+		},
+		{
+			"no fieldset",
+			"api.example.com/books?page_size=1",
+			"api.example.com/books?page=2&page_size=1",
+			`[{"id":0,"author":"jk","name":"book"}]`,
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		pgt := pg.Parse(test.link)
+
+		if err := pgt.Err(); (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+		}
+
+		paginated := pgt.WrapWithTruncate(TrunctableBooks(books), total)
+		if paginated.Pagination.Next != test.next {
+			t.Errorf("%d. [%s] next: got %s, want %s", i, test.testName, paginated.Pagination.Next, test.next)
+		}
+
+		result, err := json.Marshal(paginated.Result)
+		if err != nil {
+			t.Fatalf("%d. [%s] marshal: %v", i, test.testName, err)
+		}
+		if string(result) != test.result {
+			t.Errorf("%d. [%s] result: got %s, want %s", i, test.testName, result, test.result)
+		}
+	}
+}
+
+func TestFieldsetDefault(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		Envelope: pagination.EnvelopeDRF,
+		Fieldset: &pagination.FieldsetConfiguration{Param: "only", Default: "id"},
+	})
+
+	pgt := pg.Parse("api.example.com/books?page_size=2&page=10")
+	if got := pgt.Fieldset().String(); got != "id" {
+		t.Errorf("default fieldset: got %s, want id", got)
+	}
+
+	body, err := json.Marshal(pgt.WrapWithTruncate(TrunctableBooks(books), total))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"count":20,"next":null,"previous":"api.example.com/books?only=id\u0026page=9\u0026page_size=2","results":[{"id":18},{"id":19}]}`
+	if string(body) != want {
+		t.Errorf("envelope: got %s, want %s", body, want)
+	}
+
+	_, page, paginated, err := pgt.SetPageInfo(1, 2).WrapSlice(books, nil)
+	if err != nil {
+		t.Fatalf("wrap slice: %v", err)
+	}
+	if body, _ := json.Marshal(page); string(body) != `[{"id":0,"author":"jk","name":"book"},{"id":1,"author":"jk","name":"book"}]` {
+		t.Errorf("wrap slice page: got %s, want the unprojected page", body)
+	}
+	if body, _ := json.Marshal(paginated.Result); string(body) != `[{"id":0},{"id":1}]` {
+		t.Errorf("wrap slice result: got %s", body)
+	}
+	if objects, ok := paginated.Result.(pagination.Objects); !ok || len(objects) != 2 || string(objects[1]) != `{"id":1}` {
+		t.Errorf("wrap slice result: got %#v, want the projected Objects", paginated.Result)
+	}
+}
@@ -0,0 +1,146 @@
+// Package fieldsets parses sparse fieldsets such as `fields=id,name` and projects items to the selected JSON fields.
+package fieldsets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Fields defines the selected fields in the requested order
+type Fields []string
+
+// String returns the normalized expression of the fields, e.g. `id,name`
+func (fs Fields) String() string {
+	return strings.Join(fs, ",")
+}
+
+// Has returns whether the field is selected
+func (fs Fields) Has(field string) bool {
+	for _, f := range fs {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnselectableFieldError is returned when a field is not in the selectable fields whitelist
+type UnselectableFieldError struct {
+	Field string
+}
+
+func (e *UnselectableFieldError) Error() string {
+	return fmt.Sprintf("fieldsets: field %q is not selectable", e.Field)
+}
+
+func isIdentifier(field string) bool {
+	if field == "" {
+		return false
+	}
+
+	for _, r := range field {
+		if !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse parses a comma separated fieldset.
+// If allowed is not empty, only the listed fields are selectable.
+// Unselectable and duplicated fields are dropped, the first unselectable field is reported by the error.
+func Parse(raw string, allowed []string) (fields Fields, err error) {
+	whitelist := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		whitelist[field] = true
+	}
+
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if !isIdentifier(field) || len(whitelist) > 0 && !whitelist[field] {
+			if err == nil {
+				err = &UnselectableFieldError{field}
+			}
+			continue
+		}
+
+		if fields.Has(field) {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return
+}
+
+// Projector is implemented by items which project themselves to the selected fields,
+// the returned value is encoded to JSON in place of the item
+type Projector interface {
+	Project(fields Fields) interface{}
+}
+
+// Project returns the JSON encoding of the item keeping only the selected fields of the object.
+// The fields keep the order of the item encoding, the items implementing Projector project themselves,
+// the items which aren't encoded to an object are kept as they are.
+func Project(item interface{}, fields Fields) (json.RawMessage, error) {
+	if projector, ok := item.(Projector); ok {
+		return json.Marshal(projector.Project(fields))
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return ProjectJSON(data, fields)
+}
+
+// ProjectJSON keeps only the selected fields of the JSON object, the other JSON values are kept as they are
+func ProjectJSON(data []byte, fields Fields) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return data, nil
+	}
+
+	buf := bytes.NewBufferString("{")
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if !fields.Has(key) {
+			continue
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+package fieldsets
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	allowed := []string{"id", "name", "author"}
+
+	tests := []struct {
+		testName   string
+		raw        string
+		normalized string
+		hasErr     bool
+	}{
+		{"empty input", "", "", false},
+		{"single field", "name", "name", false},
+		{"keeps the requested order", "name,id", "name,id", false},
+		{"spaces and empty terms", " id, ,name ", "id,name", false},
+		{"duplicated field", "id,name,id", "id,name", false},
+		{"unselectable field", "password,name", "name", true},
+		{"invalid identifier", "name;drop", "", true},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.testName)
+
+		fields, err := Parse(test.raw, allowed)
+
+		if fields.String() != test.normalized {
+			t.Errorf("%s[normalized]: got %s, want %s", descr, fields.String(), test.normalized)
+		}
+		if (err != nil) != test.hasErr {
+			t.Errorf("%s[error]: got %v, want error: %v", descr, err, test.hasErr)
+		}
+		if _, ok := err.(*UnselectableFieldError); err != nil && !ok {
+			t.Errorf("%s[error type]: got %T, want *UnselectableFieldError", descr, err)
+		}
+	}
+
+	if fields, err := Parse("anything,goes", nil); err != nil || fields.String() != "anything,goes" {
+		t.Errorf("empty whitelist: got %s, %v, want anything,goes", fields, err)
+	}
+}
+
+type book struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Author string `json:"author,omitempty"`
+}
+
+type summary struct {
+	ID int
+}
+
+func (s summary) Project(fields Fields) interface{} {
+	return map[string]interface{}{"id": s.ID, "fields": fields.String()}
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		testName string
+		item     interface{}
+		fields   Fields
+		want     string
+	}{
+		{"struct", book{1, "go", "jk"}, Fields{"name", "id"}, `{"id":1,"name":"go"}`},
+		{"omitted field", book{ID: 1, Name: "go"}, Fields{"author", "id"}, `{"id":1}`},
+		{"no field", book{1, "go", "jk"}, Fields{"isbn"}, `{}`},
+		{"map", map[string]interface{}{"b": 2, "a": 1, "c": 3}, Fields{"c", "a"}, `{"a":1,"c":3}`},
+		{"nested value", map[string]interface{}{"a": []int{1, 2}, "b": 2}, Fields{"a"}, `{"a":[1,2]}`},
+		{"projector", summary{7}, Fields{"id"}, `{"fields":"id","id":7}`},
+		{"not an object", 42, Fields{"id"}, `42`},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.testName)
+
+		got, err := Project(test.item, test.fields)
+		if err != nil {
+			t.Errorf("%s[error]: %v", descr, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s[projected]: got %s, want %s", descr, got, test.want)
+		}
+	}
+
+	if _, err := ProjectJSON([]byte(`{"id":`), Fields{"id"}); err == nil || !strings.Contains(err.Error(), "EOF") {
+		t.Errorf("malformed JSON: got %v, want an EOF error", err)
+	}
+}
@@ -0,0 +1,253 @@
+package filters
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var sqlOperators = map[Operator]string{
+	Eq:  "=",
+	Ne:  "<>",
+	Lt:  "<",
+	Le:  "<=",
+	Gt:  ">",
+	Ge:  ">=",
+	In:  "IN",
+	Out: "NOT IN",
+}
+
+// SQL translates the expression into a WHERE clause body with `?` placeholders and its arguments.
+// The column callback maps a field name to its column, the field name is used if it is nil.
+func SQL(expr Expr, column func(field string) string) (where string, args []interface{}) {
+	if column == nil {
+		column = func(field string) string { return field }
+	}
+
+	var build func(expr Expr, nested bool) string
+	buildAll := func(exprs []Expr, sep string, nested bool) string {
+		terms := make([]string, len(exprs))
+		for i, e := range exprs {
+			terms[i] = build(e, true)
+		}
+		clause := strings.Join(terms, sep)
+		if nested && len(exprs) > 1 {
+			return "(" + clause + ")"
+		}
+		return clause
+	}
+	build = func(expr Expr, nested bool) string {
+		switch e := expr.(type) {
+		case And:
+			return buildAll(e, " AND ", nested)
+		case Or:
+			return buildAll(e, " OR ", nested)
+		case *Comparison:
+			args = append(args, e.Values...)
+			if e.Operator.multiValued() {
+				placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(e.Values)), ", ")
+				return column(e.Field) + " " + sqlOperators[e.Operator] + " (" + placeholders + ")"
+			}
+			return column(e.Field) + " " + sqlOperators[e.Operator] + " ?"
+		}
+		return ""
+	}
+
+	if expr == nil {
+		return "", nil
+	}
+
+	return build(expr, false), args
+}
+
+// Accessor returns the value of the named field of an item, ok is false if the field doesn't exist
+type Accessor func(item interface{}, field string) (value interface{}, ok bool)
+
+// Predicate returns a function reporting whether an item matches the expression,
+// a nil expression matches all items
+func Predicate(expr Expr, get Accessor) func(item interface{}) bool {
+	return func(item interface{}) bool {
+		return Match(expr, item, get)
+	}
+}
+
+// Match reports whether an item matches the expression
+func Match(expr Expr, item interface{}, get Accessor) bool {
+	switch e := expr.(type) {
+	case nil:
+		return true
+	case And:
+		for _, operand := range e {
+			if !Match(operand, item, get) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, operand := range e {
+			if Match(operand, item, get) {
+				return true
+			}
+		}
+		return false
+	case *Comparison:
+		value, ok := get(item, e.Field)
+		if !ok {
+			return false
+		}
+		return e.match(value)
+	}
+
+	return false
+}
+
+func (c *Comparison) match(value interface{}) bool {
+	switch c.Operator {
+	case In, Out:
+		found := false
+		for _, v := range c.Values {
+			if result, ok := Compare(value, v); ok && result == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (c.Operator == In)
+	}
+
+	if len(c.Values) == 0 {
+		return false
+	}
+
+	result, ok := Compare(value, c.Values[0])
+	if !ok {
+		return c.Operator == Ne
+	}
+
+	switch c.Operator {
+	case Eq:
+		return result == 0
+	case Ne:
+		return result != 0
+	case Lt:
+		return result < 0
+	case Le:
+		return result <= 0
+	case Gt:
+		return result > 0
+	case Ge:
+		return result >= 0
+	}
+
+	return false
+}
+
+// Compare compares two values of numeric, string, bool or time.Time types,
+// a string is converted to the type of the other value when their types differ.
+// ok is false if the values aren't comparable.
+func Compare(a, b interface{}) (result int, ok bool) {
+	if s, isString := b.(string); isString {
+		if _, isString := a.(string); !isString {
+			if b, ok = convertTo(a, s); !ok {
+				return 0, false
+			}
+		}
+	} else if s, isString := a.(string); isString {
+		if a, ok = convertTo(b, s); !ok {
+			return 0, false
+		}
+	}
+
+	if at, isTime := a.(time.Time); isTime {
+		bt, isTime := b.(time.Time)
+		if !isTime {
+			return 0, false
+		}
+		return sign(at.Before(bt), at.After(bt)), true
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !av.IsValid() || !bv.IsValid() {
+		return 0, false
+	}
+
+	switch {
+	case isInt(av) && isInt(bv):
+		return sign(av.Int() < bv.Int(), av.Int() > bv.Int()), true
+	case isNumber(av) && isNumber(bv):
+		af, bf := toFloat(av), toFloat(bv)
+		return sign(af < bf, af > bf), true
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		as, bs := av.String(), bv.String()
+		return sign(as < bs, as > bs), true
+	case av.Kind() == reflect.Bool && bv.Kind() == reflect.Bool:
+		ab, bb := av.Bool(), bv.Bool()
+		return sign(!ab && bb, ab && !bb), true
+	}
+
+	return 0, false
+}
+
+func sign(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+
+	return 0
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+
+	return float64(v.Int())
+}
+
+func convertTo(sample interface{}, s string) (interface{}, bool) {
+	var field Field
+
+	switch v := reflect.ValueOf(sample); {
+	case !v.IsValid():
+		return nil, false
+	case isInt(v):
+		field.Type = Int
+	case isNumber(v):
+		field.Type = Float
+	case v.Kind() == reflect.Bool:
+		field.Type = Bool
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		field.Type = Time
+	default:
+		return s, true
+	}
+
+	value, err := field.convert(s)
+
+	return value, err == nil
+}
@@ -0,0 +1,273 @@
+// Package filters parses filter expressions into a typed AST.
+//
+// Two syntaxes are supported:
+//
+// -- RSQL/FIQL, e.g. `filter=author==jk;year=gt=2000`
+//
+// -- LHS brackets, e.g. `price[gte]=10&price[lt]=20`
+//
+// An expression can be validated against a declared Schema,
+// rendered back to its canonical RSQL form,
+// translated into a SQL WHERE clause or evaluated against in-memory items.
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operator defines a comparison operator in its canonical RSQL form
+type Operator string
+
+// Operators
+const (
+	Eq  Operator = "=="
+	Ne  Operator = "!="
+	Lt  Operator = "=lt="
+	Le  Operator = "=le="
+	Gt  Operator = "=gt="
+	Ge  Operator = "=ge="
+	In  Operator = "=in="
+	Out Operator = "=out="
+)
+
+func (op Operator) multiValued() bool {
+	return op == In || op == Out
+}
+
+// Expr defines a node of the filter AST, it is one of And, Or and *Comparison
+type Expr interface {
+	// String returns the canonical RSQL form of the expression
+	String() string
+}
+
+// And matches when all of the operands match
+type And []Expr
+
+// Or matches when any of the operands matches
+type Or []Expr
+
+// Comparison compares a field with the arguments.
+// Args holds the raw arguments, Values holds them converted by Schema::Validate,
+// they are the raw strings if the expression isn't validated.
+type Comparison struct {
+	Field    string
+	Operator Operator
+	Args     []string
+	Values   []interface{}
+}
+
+func joinExprs(exprs []Expr, sep string, wrap func(Expr) bool) string {
+	terms := make([]string, len(exprs))
+	for i, expr := range exprs {
+		terms[i] = expr.String()
+		if wrap(expr) {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+
+	return strings.Join(terms, sep)
+}
+
+func (a And) String() string {
+	return joinExprs(a, ";", func(expr Expr) bool {
+		_, isOr := expr.(Or)
+		return isOr
+	})
+}
+
+func (o Or) String() string {
+	return joinExprs(o, ",", func(Expr) bool { return false })
+}
+
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, reservedChars) {
+		return arg
+	}
+
+	return "'" + strings.Replace(strings.Replace(arg, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+}
+
+func (c *Comparison) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = quoteArg(arg)
+	}
+
+	if c.Operator.multiValued() {
+		return c.Field + string(c.Operator) + "(" + strings.Join(args, ",") + ")"
+	}
+
+	return c.Field + string(c.Operator) + strings.Join(args, ",")
+}
+
+// Type defines the value type of a filterable field
+type Type int
+
+// Types
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+)
+
+var typeNames = []string{"string", "int", "float", "bool", "time"}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+
+	return typeNames[t]
+}
+
+// MarshalText encodes the type by its name, e.g. "int"
+func (t Type) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(typeNames) {
+		return nil, fmt.Errorf("filters: unknown type %d", int(t))
+	}
+
+	return []byte(typeNames[t]), nil
+}
+
+// UnmarshalText decodes the type from its name, e.g. "int"
+func (t *Type) UnmarshalText(text []byte) error {
+	for i, name := range typeNames {
+		if name == string(text) {
+			*t = Type(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("filters: unknown type %q", text)
+}
+
+// Field declares a filterable field, an empty Operators list allows all operators
+type Field struct {
+	Type      Type       `json:"type"`
+	Operators []Operator `json:"operators,omitempty"`
+}
+
+func (f Field) allows(op Operator) bool {
+	if len(f.Operators) == 0 {
+		return true
+	}
+
+	for _, allowed := range f.Operators {
+		if allowed == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Field) convert(arg string) (interface{}, error) {
+	switch f.Type {
+	case Int:
+		return strconv.ParseInt(arg, 10, 64)
+	case Float:
+		return strconv.ParseFloat(arg, 64)
+	case Bool:
+		return strconv.ParseBool(arg)
+	case Time:
+		if t, err := time.Parse(time.RFC3339, arg); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", arg)
+	}
+
+	return arg, nil
+}
+
+// Schema declares the filterable fields by their names
+type Schema map[string]Field
+
+// ValidationError is returned when an expression doesn't conform to the Schema
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("filters: field %q %s", e.Field, e.Reason)
+}
+
+// Validate checks the expression against the schema,
+// and returns a copy of it whose comparison values are converted to the declared types
+func (s Schema) Validate(expr Expr) (Expr, error) {
+	switch e := expr.(type) {
+	case And:
+		operands, err := s.validateAll(e)
+		return And(operands), err
+	case Or:
+		operands, err := s.validateAll(e)
+		return Or(operands), err
+	case *Comparison:
+		field, ok := s[e.Field]
+		if !ok {
+			return nil, &ValidationError{e.Field, "is not filterable"}
+		}
+		if !field.allows(e.Operator) {
+			return nil, &ValidationError{e.Field, fmt.Sprintf("doesn't support operator %s", e.Operator)}
+		}
+
+		validated := &Comparison{Field: e.Field, Operator: e.Operator, Args: e.Args}
+		for _, arg := range e.Args {
+			value, err := field.convert(arg)
+			if err != nil {
+				return nil, &ValidationError{e.Field, fmt.Sprintf("has an invalid value %q", arg)}
+			}
+			validated.Values = append(validated.Values, value)
+		}
+
+		return validated, nil
+	}
+
+	return nil, fmt.Errorf("filters: unknown expression %T", expr)
+}
+
+func (s Schema) validateAll(exprs []Expr) ([]Expr, error) {
+	validated := make([]Expr, len(exprs))
+	for i, expr := range exprs {
+		v, err := s.Validate(expr)
+		if err != nil {
+			return nil, err
+		}
+		validated[i] = v
+	}
+
+	return validated, nil
+}
+
+// Fields returns the names of the fields referenced by the expression
+func Fields(expr Expr) []string {
+	var fields []string
+	seen := map[string]bool{}
+
+	var walk func(Expr)
+	walk = func(expr Expr) {
+		switch e := expr.(type) {
+		case And:
+			for _, operand := range e {
+				walk(operand)
+			}
+		case Or:
+			for _, operand := range e {
+				walk(operand)
+			}
+		case *Comparison:
+			if !seen[e.Field] {
+				seen[e.Field] = true
+				fields = append(fields, e.Field)
+			}
+		}
+	}
+	walk(expr)
+
+	return fields
+}
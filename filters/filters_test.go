@@ -0,0 +1,210 @@
+package filters
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRSQL(t *testing.T) {
+	tests := []struct {
+		testName  string
+		input     string
+		canonical string
+		hasErr    bool
+	}{
+		{"empty input", "", "", false},
+		{"single comparison", "author==jk", "author==jk", false},
+		{"conjunction", "author==jk;year>2000", "author==jk;year=gt=2000", false},
+		{"disjunction in conjunction", "author==jk;(year=lt=1900,year>=2000)", "author==jk;(year=lt=1900,year=ge=2000)", false},
+		{"conjunction in disjunction", "a==1;b==2,c==3", "a==1;b==2,c==3", false},
+		{"multi-valued arguments", "genre=in=(fantasy, 'sci fi')", "genre=in=(fantasy,'sci fi')", false},
+		{"quoted argument", `name=="O\'Reilly"`, `name=='O\'Reilly'`, false},
+		{"named operators", "a=ne=1;b=le=2;c=out=(3)", "a!=1;b=le=2;c=out=(3)", false},
+		{"missing argument", "author==", "", true},
+		{"unknown operator", "author=like=jk", "", true},
+		{"single argument operator with list", "year==(1,2)", "", true},
+		{"unbalanced parenthesis", "(author==jk", "", true},
+		{"unterminated quote", "author=='jk", "", true},
+		{"trailing garbage", "author==jk)", "", true},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.testName)
+
+		expr, err := ParseRSQL(test.input)
+
+		if (err != nil) != test.hasErr {
+			t.Errorf("%s[error]: got %v, want error: %v", descr, err, test.hasErr)
+			continue
+		}
+		canonical := ""
+		if expr != nil {
+			canonical = expr.String()
+		}
+		if canonical != test.canonical {
+			t.Errorf("%s[canonical]: got %s, want %s", descr, canonical, test.canonical)
+		}
+	}
+}
+
+func TestParseBrackets(t *testing.T) {
+	query := url.Values{
+		"author":      {"jk"},
+		"price[gte]":  {"10"},
+		"price[lt]":   {"20"},
+		"genre[nin]":  {"horror,crime"},
+		"page":        {"2"},
+		"unknown[]id": {"1"},
+	}
+
+	expr, keys, err := ParseBrackets(query)
+	if err != nil {
+		t.Fatalf("[brackets] unexpected error: %v", err)
+	}
+	if canonical := "genre=out=(horror,crime);price=ge=10;price=lt=20"; expr.String() != canonical {
+		t.Errorf("[brackets canonical]: got %s, want %s", expr.String(), canonical)
+	}
+	if !reflect.DeepEqual(keys, []string{"genre[nin]", "price[gte]", "price[lt]"}) {
+		t.Errorf("[brackets keys]: got %v", keys)
+	}
+
+	if _, _, err := ParseBrackets(url.Values{"price[between]": {"1"}}); err == nil {
+		t.Errorf("[brackets unknown operator]: expects an error")
+	}
+}
+
+var schema = Schema{
+	"author":  {Type: String, Operators: []Operator{Eq, Ne, In}},
+	"year":    {Type: Int},
+	"price":   {Type: Float},
+	"instock": {Type: Bool},
+	"since":   {Type: Time},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input  string
+		values []interface{}
+		hasErr bool
+	}{
+		{"year=gt=2000", []interface{}{int64(2000)}, false},
+		{"price<9.5", []interface{}{9.5}, false},
+		{"instock==true", []interface{}{true}, false},
+		{"since=ge=2018-01-02", []interface{}{time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)}, false},
+		{"author=in=(jk,tolkien)", []interface{}{"jk", "tolkien"}, false},
+		{"year==abc", nil, true},
+		{"author=gt=jk", nil, true},
+		{"isbn==1", nil, true},
+	}
+
+	for i, test := range tests {
+		expr, _ := ParseRSQL(test.input)
+		validated, err := schema.Validate(expr)
+
+		if (err != nil) != test.hasErr {
+			t.Errorf("%d. [validate %s] error: got %v, want error: %v", i, test.input, err, test.hasErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(validated.(*Comparison).Values, test.values) {
+			t.Errorf("%d. [validate %s] values: got %#v, want %#v", i, test.input, validated.(*Comparison).Values, test.values)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	expr, _ := ParseRSQL("author==jk;(year<1900,year=ge=2000);genre=out=(horror,crime)")
+
+	where, args := SQL(expr, func(field string) string { return "books." + field })
+
+	if want := "books.author = ? AND (books.year < ? OR books.year >= ?) AND books.genre NOT IN (?, ?)"; where != want {
+		t.Errorf("[sql where]: got %s, want %s", where, want)
+	}
+	if want := []interface{}{"jk", "1900", "2000", "horror", "crime"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("[sql args]: got %v, want %v", args, want)
+	}
+
+	if where, args := SQL(nil, nil); where != "" || args != nil {
+		t.Errorf("[sql nil]: got %s, %v", where, args)
+	}
+}
+
+type book struct {
+	Author string
+	Year   int
+	Price  float64
+}
+
+func TestMatch(t *testing.T) {
+	get := func(item interface{}, field string) (interface{}, bool) {
+		b := item.(book)
+		switch field {
+		case "author":
+			return b.Author, true
+		case "year":
+			return b.Year, true
+		case "price":
+			return b.Price, true
+		}
+		return nil, false
+	}
+
+	books := []book{{"jk", 1997, 9.9}, {"jk", 2007, 19.9}, {"tolkien", 1954, 15}}
+
+	tests := []struct {
+		input   string
+		matches []bool
+	}{
+		{"", []bool{true, true, true}},
+		{"author==jk", []bool{true, true, false}},
+		{"author==jk;year>2000", []bool{false, true, false}},
+		{"year<1960,price=le=9.9", []bool{true, false, true}},
+		{"author=out=(jk)", []bool{false, false, true}},
+		{"price>10.5", []bool{false, true, true}},
+		{"isbn==1", []bool{false, false, false}},
+	}
+
+	for i, test := range tests {
+		expr, err := ParseRSQL(test.input)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %v", i, err)
+		}
+
+		predicate := Predicate(expr, get)
+		for j, b := range books {
+			if predicate(b) != test.matches[j] {
+				t.Errorf("%d. [match %s] book %d: got %v, want %v", i, test.input, j, !test.matches[j], test.matches[j])
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		a, b   interface{}
+		result int
+		ok     bool
+	}{
+		{1, int64(2), -1, true},
+		{uint8(3), 2.5, 1, true},
+		{"b", "a", 1, true},
+		{false, true, -1, true},
+		{now, now.Add(time.Second), -1, true},
+		{10, "10", 0, true},
+		{"2.5", 2.5, 0, true},
+		{10, "ten", 0, false},
+		{nil, 1, 0, false},
+		{struct{}{}, 1, 0, false},
+	}
+
+	for i, test := range tests {
+		result, ok := Compare(test.a, test.b)
+		if result != test.result || ok != test.ok {
+			t.Errorf("%d. [compare %v, %v]: got (%d, %v), want (%d, %v)", i, test.a, test.b, result, ok, test.result, test.ok)
+		}
+	}
+}
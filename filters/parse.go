@@ -0,0 +1,338 @@
+package filters
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const reservedChars = "\"'();,=!~<> "
+
+// SyntaxError is returned when a RSQL/FIQL expression is malformed
+type SyntaxError struct {
+	Input  string
+	Offset int
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filters: %s at offset %d of %q", e.Reason, e.Offset, e.Input)
+}
+
+var namedOperators = map[string]Operator{
+	"eq":  Eq,
+	"ne":  Ne,
+	"lt":  Lt,
+	"le":  Le,
+	"gt":  Gt,
+	"ge":  Ge,
+	"in":  In,
+	"out": Out,
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) fail(reason string) error {
+	return &SyntaxError{p.input, p.pos, reason}
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	operands, err := p.parseList(',', p.parseAnd)
+	if err != nil || len(operands) == 1 {
+		return operands[0], err
+	}
+
+	return Or(operands), nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	operands, err := p.parseList(';', p.parseConstraint)
+	if err != nil || len(operands) == 1 {
+		return operands[0], err
+	}
+
+	return And(operands), nil
+}
+
+func (p *parser) parseList(sep byte, parseOperand func() (Expr, error)) ([]Expr, error) {
+	var operands []Expr
+
+	for {
+		operand, err := parseOperand()
+		if err != nil {
+			return []Expr{nil}, err
+		}
+		operands = append(operands, operand)
+
+		if p.peek() != sep {
+			return operands, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseConstraint() (Expr, error) {
+	if p.peek() != '(' {
+		return p.parseComparison()
+	}
+
+	p.pos++
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ')' {
+		return nil, p.fail("missing closing parenthesis")
+	}
+	p.pos++
+
+	return expr, nil
+}
+
+func isSelectorChar(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && isSelectorChar(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.fail("missing selector")
+	}
+
+	comparison := &Comparison{Field: p.input[start:p.pos]}
+
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	comparison.Operator = op
+
+	if p.peek() == '(' {
+		p.pos++
+		for {
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			comparison.Args = append(comparison.Args, arg)
+
+			c := p.peek()
+			p.pos++
+			if c == ')' {
+				break
+			}
+			if c != ',' {
+				p.pos--
+				return nil, p.fail("missing closing parenthesis")
+			}
+		}
+	} else {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		comparison.Args = []string{arg}
+	}
+
+	if !op.multiValued() && len(comparison.Args) != 1 {
+		return nil, p.fail(fmt.Sprintf("operator %s accepts a single argument", op))
+	}
+
+	for _, arg := range comparison.Args {
+		comparison.Values = append(comparison.Values, arg)
+	}
+
+	return comparison, nil
+}
+
+func (p *parser) parseOperator() (Operator, error) {
+	p.skipSpaces()
+	rest := p.input[p.pos:]
+
+	for _, symbol := range []struct {
+		text string
+		op   Operator
+	}{{"==", Eq}, {"!=", Ne}, {"<=", Le}, {">=", Ge}, {"<", Lt}, {">", Gt}} {
+		if strings.HasPrefix(rest, symbol.text) {
+			p.pos += len(symbol.text)
+			return symbol.op, nil
+		}
+	}
+
+	if strings.HasPrefix(rest, "=") {
+		if end := strings.IndexByte(rest[1:], '='); end >= 0 {
+			if op, ok := namedOperators[rest[1:end+1]]; ok {
+				p.pos += end + 2
+				return op, nil
+			}
+		}
+	}
+
+	return "", p.fail("unknown operator")
+}
+
+func (p *parser) parseArg() (string, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return "", p.fail("missing argument")
+	}
+
+	quote := p.input[p.pos]
+	if quote != '\'' && quote != '"' {
+		start := p.pos
+		for p.pos < len(p.input) && !strings.ContainsRune(reservedChars, rune(p.input[p.pos])) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.fail("missing argument")
+		}
+		return p.input[start:p.pos], nil
+	}
+
+	var arg strings.Builder
+	for p.pos++; p.pos < len(p.input); p.pos++ {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input):
+			p.pos++
+			arg.WriteByte(p.input[p.pos])
+		case c == quote:
+			p.pos++
+			return arg.String(), nil
+		default:
+			arg.WriteByte(c)
+		}
+	}
+
+	return "", p.fail("unterminated quoted argument")
+}
+
+// ParseRSQL parses a RSQL/FIQL expression, e.g. `author==jk;(year=gt=2000,year<1900)`.
+// Both the FIQL named operators (`=lt=`) and their RSQL aliases (`<`) are accepted,
+// an empty input results in a nil expression.
+func ParseRSQL(input string) (Expr, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+
+	p := &parser{input: input}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, p.fail("unexpected character")
+	}
+
+	return expr, nil
+}
+
+var bracketOperators = map[string]Operator{
+	"eq":  Eq,
+	"ne":  Ne,
+	"lt":  Lt,
+	"lte": Le,
+	"le":  Le,
+	"gt":  Gt,
+	"gte": Ge,
+	"ge":  Ge,
+	"in":  In,
+	"nin": Out,
+	"out": Out,
+}
+
+// ParseBrackets collects the LHS bracket parameters from the query, e.g. `price[gte]=10`,
+// and returns them as a conjunction. Multi-valued operators take comma separated values.
+// The keys of the collected parameters are returned for removing them from links.
+func ParseBrackets(query url.Values) (expr Expr, keys []string, err error) {
+	for key := range query {
+		if open := strings.IndexByte(key, '['); open > 0 && strings.HasSuffix(key, "]") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var operands And
+	for _, key := range keys {
+		open := strings.IndexByte(key, '[')
+		field, name := key[:open], strings.ToLower(key[open+1:len(key)-1])
+
+		op, ok := bracketOperators[name]
+		if !ok {
+			return nil, keys, &SyntaxError{key, open + 1, "unknown operator"}
+		}
+		for i := 0; i < len(field); i++ {
+			if !isSelectorChar(field[i]) {
+				return nil, keys, &SyntaxError{key, i, "invalid selector"}
+			}
+		}
+
+		for _, value := range query[key] {
+			args := []string{value}
+			if op.multiValued() {
+				args = strings.Split(value, ",")
+			}
+
+			comparison := &Comparison{Field: field, Operator: op, Args: args}
+			for _, arg := range args {
+				comparison.Values = append(comparison.Values, arg)
+			}
+			operands = append(operands, comparison)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil, keys, nil
+	case 1:
+		return operands[0], keys, nil
+	}
+
+	return operands, keys, nil
+}
+
+// Join combines the non-nil expressions into a conjunction
+func Join(exprs ...Expr) Expr {
+	var operands And
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case nil:
+		case And:
+			operands = append(operands, e...)
+		default:
+			operands = append(operands, e)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	}
+
+	return operands
+}
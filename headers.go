@@ -0,0 +1,37 @@
+package pagination
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LinkHeader returns the RFC 8288 Link header of the navigation links, e.g. `</books?page=3>; rel="next"`.
+// The prev and next links are omitted on the first and the last pages, and the last link is omitted if the total is unknown
+func (f *PageFields) LinkHeader() string {
+	var links []string
+	add := func(link, rel string) {
+		if link != "" {
+			links = append(links, "<"+link+`>; rel="`+rel+`"`)
+		}
+	}
+
+	add(f.First, "first")
+	if f.hasPrev() {
+		add(f.Prev, "prev")
+	}
+	if f.hasNext() {
+		add(f.Next, "next")
+	}
+	add(f.Last, "last")
+
+	return strings.Join(links, ", ")
+}
+
+// WriteHeaders sets the Link and X-Total-Count headers, it should be called before writing the response body
+func (f *PageFields) WriteHeaders(header http.Header) {
+	if link := f.LinkHeader(); link != "" {
+		header.Set("Link", link)
+	}
+	header.Set("X-Total-Count", strconv.FormatInt(f.Total, 10))
+}
@@ -0,0 +1,63 @@
+package pagination_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func TestWriteHeaders(t *testing.T) {
+	tests := []struct {
+		testName string
+		link     string
+		cfg      pagination.PaginatorConfiguration
+		total    int64
+		header   string
+	}{
+		{
+			"middle page",
+			requestURI,
+			pagination.PaginatorConfiguration{},
+			total,
+			`<api.example.com/books?author=jk&page=1&page_size=5>; rel="first", <api.example.com/books?author=jk&page=1&page_size=5>; rel="prev", ` +
+				`<api.example.com/books?author=jk&page=3&page_size=5>; rel="next", <api.example.com/books?author=jk&page=4&page_size=5>; rel="last"`,
+		},
+		{
+			"first page",
+			"/books?page_size=10",
+			pagination.PaginatorConfiguration{},
+			total,
+			`</books?page=1&page_size=10>; rel="first", </books?page=2&page_size=10>; rel="next", </books?page=2&page_size=10>; rel="last"`,
+		},
+		{
+			"last zero-based page",
+			"/books?page=1&page_size=10",
+			pagination.PaginatorConfiguration{ZeroBasedPage: true},
+			total,
+			`</books?page=0&page_size=10>; rel="first", </books?page=0&page_size=10>; rel="prev", </books?page=1&page_size=10>; rel="last"`,
+		},
+		{
+			"unknown total",
+			"/books?page=2&page_size=10",
+			pagination.PaginatorConfiguration{},
+			0,
+			`</books?page=1&page_size=10>; rel="first", </books?page=1&page_size=10>; rel="prev", </books?page=3&page_size=10>; rel="next"`,
+		},
+	}
+
+	for i, test := range tests {
+		paginated := pagination.NewPagination(test.cfg).Parse(test.link).WrapWithTruncate(TrunctableBooks(books), test.total)
+
+		header := http.Header{}
+		paginated.Pagination.WriteHeaders(header)
+
+		if got := header.Get("Link"); got != test.header {
+			t.Errorf("%d. [%s] Link:\ngot  %s\nwant %s", i, test.testName, got, test.header)
+		}
+		if got, want := header.Get("X-Total-Count"), paginated.Pagination.Total; got != strconv.FormatInt(want, 10) {
+			t.Errorf("%d. [%s] X-Total-Count: got %s, want %d", i, test.testName, got, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"strings"
+)
+
+// LinkFormat defines the form of the navigation links
+type LinkFormat int
+
+// Link formats
+const (
+	// LinkAbsolute keeps the origin of the parsed link or uses the BaseURL, e.g. "https://api.example.com/v2/books?page=2"
+	LinkAbsolute LinkFormat = iota
+	// LinkRootRelative drops the origin, e.g. "/v2/books?page=2"
+	LinkRootRelative
+	// LinkQueryOnly drops the origin and the path, e.g. "?page=2"
+	LinkQueryOnly
+)
+
+// LinkConfiguration defines how the navigation links are rewritten. By default:
+//
+// -- BaseURL: empty, the origin of the parsed link is kept. It is the public origin, e.g. "https://api.example.com"
+//
+// -- StripPrefix: empty, the path prefix removed from the parsed path, e.g. "/internal"
+//
+// -- PathPrefix: empty, the path prefix prepended to the path, e.g. "/v2"
+//
+// -- Format: LinkAbsolute
+//
+// -- Template: empty, a RFC 6570 URI template of the whole link replacing the ones above,
+// e.g. "/v2/shops/{shop}/books{?author,page,page_size}". It is expanded by the query parameters
+// and the values set by Paginator::SetTemplateValues, and the templated link to any page is emitted
+type LinkConfiguration struct {
+	BaseURL     string     `json:"base_url,omitempty"`
+	StripPrefix string     `json:"strip_prefix,omitempty"`
+	PathPrefix  string     `json:"path_prefix,omitempty"`
+	Format      LinkFormat `json:"format,omitempty"`
+	Template    string     `json:"template,omitempty"`
+}
+
+// splitBasePath splits the base path into its origin and path.
+// A link without scheme, e.g. "api.example.com/books", takes its first segment as the host.
+func splitBasePath(basePath string) (origin, path string) {
+	rest := basePath
+	if scheme := strings.Index(rest, "://"); scheme >= 0 {
+		rest = rest[scheme+3:]
+	} else if strings.HasPrefix(rest, "/") {
+		return "", basePath
+	}
+
+	slash := strings.IndexByte(rest, '/')
+	if slash < 0 {
+		return basePath, ""
+	}
+
+	cut := len(basePath) - len(rest) + slash
+	return basePath[:cut], basePath[cut:]
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// rewrite returns the base path of the navigation links
+func (cfg LinkConfiguration) rewrite(basePath string) string {
+	origin, path := splitBasePath(basePath)
+
+	if prefix := strings.TrimSuffix(cfg.StripPrefix, "/"); prefix != "" &&
+		(path == prefix || strings.HasPrefix(path, prefix+"/")) {
+		path = "/" + strings.TrimPrefix(path[len(prefix):], "/")
+	}
+	path = joinPath(cfg.PathPrefix, path)
+
+	switch cfg.Format {
+	case LinkQueryOnly:
+		return ""
+	case LinkRootRelative:
+		return "/" + strings.TrimPrefix(path, "/")
+	}
+
+	if cfg.BaseURL != "" {
+		return joinPath(cfg.BaseURL, path)
+	}
+
+	return origin + path
+}
@@ -0,0 +1,94 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		testName string
+		links    pagination.LinkConfiguration
+		link     string
+		next     string
+	}{
+		{"untouched", pagination.LinkConfiguration{},
+			"http://books-svc.internal:8080/books?page=2",
+			"http://books-svc.internal:8080/books?page=3&page_size=30",
+		},
+		{"untouched without scheme", pagination.LinkConfiguration{},
+			"api.example.com/books?page=2",
+			"api.example.com/books?page=3&page_size=30",
+		},
+		{"public base url", pagination.LinkConfiguration{BaseURL: "https://api.example.com/"},
+			"http://books-svc.internal:8080/books?page=2",
+			"https://api.example.com/books?page=3&page_size=30",
+		},
+		{"public base url with path prefix", pagination.LinkConfiguration{BaseURL: "https://api.example.com", PathPrefix: "/v2"},
+			"http://books-svc.internal:8080/books?page=2",
+			"https://api.example.com/v2/books?page=3&page_size=30",
+		},
+		{"strip and prepend path prefix", pagination.LinkConfiguration{StripPrefix: "/internal/", PathPrefix: "/v2"},
+			"http://books-svc.internal:8080/internal/books?page=2",
+			"http://books-svc.internal:8080/v2/books?page=3&page_size=30",
+		},
+		{"strip prefix on segment boundary only", pagination.LinkConfiguration{StripPrefix: "/internal"},
+			"http://books-svc.internal:8080/internals/books?page=2",
+			"http://books-svc.internal:8080/internals/books?page=3&page_size=30",
+		},
+		{"root relative", pagination.LinkConfiguration{Format: pagination.LinkRootRelative, PathPrefix: "/v2"},
+			"http://books-svc.internal:8080/books?page=2",
+			"/v2/books?page=3&page_size=30",
+		},
+		{"root relative from request uri", pagination.LinkConfiguration{Format: pagination.LinkRootRelative},
+			"/books?page=2",
+			"/books?page=3&page_size=30",
+		},
+		{"query only", pagination.LinkConfiguration{Format: pagination.LinkQueryOnly},
+			"http://books-svc.internal:8080/books?page=2",
+			"?page=3&page_size=30",
+		},
+	}
+
+	for i, test := range tests {
+		pg := pagination.NewPagination(pagination.PaginatorConfiguration{Links: test.links})
+		fields := pg.Parse(test.link).Wrap(TrunctableBooks(books[:5]), 100).Pagination
+
+		if fields.Next != test.next {
+			t.Errorf("%d. [%s] next link: got %s, want %s", i, test.testName, fields.Next, test.next)
+		}
+	}
+}
+
+func TestPreserveQueryOrder(t *testing.T) {
+	link := "api.example.com/books?z=1&page=2&tag=b&tag=a&sort=-year&q=x%20y"
+
+	tests := []struct {
+		testName string
+		preserve bool
+		next     string
+	}{
+		{"sorted by default", false, "api.example.com/books?page=3&page_size=5&q=x+y&sort=-year&tag=b&tag=a&z=1"},
+		{"original order", true, "api.example.com/books?z=1&page=3&tag=b&tag=a&sort=-year&q=x%20y&page_size=5"},
+	}
+
+	for i, test := range tests {
+		pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+			PageSize:           5,
+			Sort:               &pagination.SortConfiguration{},
+			PreserveQueryOrder: test.preserve,
+		})
+		fields := pg.Parse(link).Wrap(TrunctableBooks(books[:5]), total).Pagination
+
+		if fields.Next != test.next {
+			t.Errorf("%d. [%s] next link: got %s, want %s", i, test.testName, fields.Next, test.next)
+		}
+	}
+
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{PageSize: 5, PreserveQueryOrder: true})
+	fields := pg.Parse("api.example.com/books?q=author==jk;year>2000&page=2").Wrap(TrunctableBooks(books[:5]), total).Pagination
+	if want := "api.example.com/books?q=author==jk;year>2000&page=3&page_size=5"; fields.Next != want {
+		t.Errorf("[semicolon pair] next link: got %s, want %s", fields.Next, want)
+	}
+}
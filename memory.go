@@ -0,0 +1,122 @@
+package pagination
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/zheeeng/pagination/filters"
+	"github.com/zheeeng/pagination/sorting"
+)
+
+// Accessors maps field names to their value getters, they feed the in-memory filtering and sorting
+type Accessors map[string]func(item interface{}) interface{}
+
+type truncatableSlice struct {
+	value reflect.Value
+}
+
+func (s truncatableSlice) Len() int {
+	return s.value.Len()
+}
+
+func (s truncatableSlice) Slice(startIndex, endIndex int) Truncatable {
+	return truncatableSlice{s.value.Slice(startIndex, endIndex)}
+}
+
+func (s truncatableSlice) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.value.Interface())
+}
+
+// TruncatableSlice returns the slice as a Truncatable, it is returned untouched if it is already a Truncatable.
+// It panics if items is not Slice kind
+func TruncatableSlice(items interface{}) Truncatable {
+	if truncatable, ok := items.(Truncatable); ok {
+		return truncatable
+	}
+
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("pagination: TruncatableSlice expects a slice, got %T", items))
+	}
+
+	return truncatableSlice{value}
+}
+
+func (a Accessors) get(item interface{}, field string) (interface{}, bool) {
+	accessor, ok := a[field]
+	if !ok {
+		return nil, false
+	}
+
+	return accessor(item), true
+}
+
+func (a Accessors) check(fields []string) error {
+	for _, field := range fields {
+		if _, ok := a[field]; !ok {
+			return fmt.Errorf("pagination: missing accessor for field %q", field)
+		}
+	}
+
+	return nil
+}
+
+func (a Accessors) less(orders sorting.Orders, x, y interface{}) bool {
+	for _, order := range orders {
+		result, _ := filters.Compare(a[order.Field](x), a[order.Field](y))
+		if result == 0 {
+			continue
+		}
+
+		return (result < 0) == (order.Direction == sorting.Asc)
+	}
+
+	return false
+}
+
+// WrapSlice filters, sorts and truncates the slice in memory by the Paginator context,
+// and returns the filtered total, the page of items and the wrapped result.
+// Sorting is stable, the items with equal sort keys keep their input order.
+// It returns an error if an accessor for a filtered or sorted field is missing,
+// or the one reported by the out-of-range policy, and it panics if items is not Slice kind
+func (p *Paginator) WrapSlice(items interface{}, accessors Accessors) (total int64, page Truncatable, paginated Paginated, err error) {
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("pagination: WrapSlice expects a slice, got %T", items))
+	}
+
+	fields := filters.Fields(p.filter)
+	for _, order := range p.sort {
+		fields = append(fields, order.Field)
+	}
+	if err = accessors.check(fields); err != nil {
+		return
+	}
+
+	indexes := make([]int, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		if filters.Match(p.filter, value.Index(i).Interface(), accessors.get) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(p.sort) > 0 {
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return accessors.less(p.sort, value.Index(indexes[i]).Interface(), value.Index(indexes[j]).Interface())
+		})
+	}
+
+	filtered := reflect.MakeSlice(value.Type(), len(indexes), len(indexes))
+	for i, index := range indexes {
+		filtered.Index(i).Set(value.Index(index))
+	}
+
+	total = int64(len(indexes))
+	truncatable := TruncatableSlice(filtered.Interface())
+	paginated, err = p.TryWrapWithTruncate(truncatable, total)
+	page = p.truncate(truncatable)
+
+	return
+}
@@ -0,0 +1,103 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+type Movie struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+}
+
+var movies = []Movie{
+	{1, "Alien", 1979},
+	{2, "Heat", 1995},
+	{3, "Blade Runner", 1982},
+	{4, "Arrival", 2016},
+	{5, "Dune", 2021},
+	{6, "Brazil", 1985},
+	{7, "Gattaca", 1997},
+}
+
+var movieAccessors = pagination.Accessors{
+	"title": func(item interface{}) interface{} { return item.(Movie).Title },
+	"year":  func(item interface{}) interface{} { return item.(Movie).Year },
+	"decade": func(item interface{}) interface{} {
+		return item.(Movie).Year / 10 * 10
+	},
+}
+
+func TestWrapSlice(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		PageSize: 2,
+		Sort:     &pagination.SortConfiguration{Default: "title"},
+		Filter:   &pagination.FilterConfiguration{},
+	})
+
+	tests := []struct {
+		testName string
+		link     string
+		total    int64
+		ids      []int
+	}{
+		{"default sort", "api.example.com/movies", 7, []int{1, 4}},
+		{"second page", "api.example.com/movies?page=2", 7, []int{3, 6}},
+		{"filter and sort", "api.example.com/movies?filter=year=lt=1990&sort=-year", 3, []int{6, 3}},
+		{"stable sort", "api.example.com/movies?sort=decade&page=2", 7, []int{6, 2}},
+		{"out of range", "api.example.com/movies?page=5", 7, []int{}},
+	}
+
+	for i, test := range tests {
+		pgt := pg.Parse(test.link)
+
+		total, page, paginated, err := pgt.WrapSlice(movies, movieAccessors)
+		if err != nil {
+			t.Fatalf("%d. [%s] unexpected error: %v", i, test.testName, err)
+		}
+
+		if total != test.total || paginated.Pagination.Total != test.total {
+			t.Errorf("%d. [%s] total: got %d, want %d", i, test.testName, total, test.total)
+		}
+
+		var got []Movie
+		encoded, _ := json.Marshal(page)
+		json.Unmarshal(encoded, &got)
+
+		if len(got) != len(test.ids) {
+			t.Errorf("%d. [%s] page: got %v, want ids %v", i, test.testName, got, test.ids)
+			continue
+		}
+		for j, movie := range got {
+			if movie.ID != test.ids[j] {
+				t.Errorf("%d. [%s] page: got %v, want ids %v", i, test.testName, got, test.ids)
+				break
+			}
+		}
+	}
+}
+
+func TestWrapSliceKeepsTruncatable(t *testing.T) {
+	pgt := pagination.DefaultPagination().Parse(requestURI)
+
+	_, page, _, err := pgt.WrapSlice(TrunctableBooks(books), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result, ok := page.(TrunctableBooks); !ok || len(result) != 5 || result[0].ID != 5 {
+		t.Errorf("[truncatable kept]: got %#v", page)
+	}
+}
+
+func TestWrapSliceMissingAccessor(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		Sort: &pagination.SortConfiguration{},
+	})
+
+	if _, _, _, err := pg.Parse("api.example.com/movies?sort=rating").WrapSlice(movies, movieAccessors); err == nil {
+		t.Errorf("[missing accessor]: expects an error")
+	}
+}
@@ -0,0 +1,68 @@
+package pagination
+
+import (
+	"net/url"
+	"strings"
+)
+
+// namespaced prefixes the parameter name with the namespace, e.g. "orders.page"
+func namespaced(namespace, param string) string {
+	if namespace == "" || strings.HasPrefix(param, namespace+".") {
+		return param
+	}
+
+	return namespace + "." + param
+}
+
+// namespacedQuery returns the query parameters in the namespace with the prefix stripped,
+// it returns the whole query if there is no namespace
+func (p *Paginator) namespacedQuery() url.Values {
+	if p.namespace == "" {
+		return p.queries.Query
+	}
+
+	prefix := p.namespace + "."
+	query := url.Values{}
+	for key, values := range p.queries.Query {
+		if strings.HasPrefix(key, prefix) {
+			query[key[len(prefix):]] = values
+		}
+	}
+
+	return query
+}
+
+// inNamespace returns whether the parameter belongs to the namespace, all the parameters do if there is no namespace
+func (p *Paginator) inNamespace(param string) bool {
+	return p.namespace == "" || strings.HasPrefix(param, p.namespace+".")
+}
+
+// Namespace returns the namespace of the parameter names, it is empty if the Paginator isn't namespaced
+func (p *Paginator) Namespace() string {
+	return p.namespace
+}
+
+// Collections paginates several collections by one link, each collection reads and writes its own namespaced parameters
+// and its navigation links keep the parameters of the others, e.g. "/dashboard?orders.page=2&tickets.page=5"
+type Collections map[string]Pagination
+
+// NewCollections returns the collections of the configurations, the namespaces are set by the collection names
+func NewCollections(cfgs map[string]PaginatorConfiguration) Collections {
+	collections := make(Collections, len(cfgs))
+	for name, cfg := range cfgs {
+		cfg.Namespace = name
+		collections[name] = NewPagination(cfg)
+	}
+
+	return collections
+}
+
+// Parse parses the link into a Paginator for each collection
+func (c Collections) Parse(link string) map[string]*Paginator {
+	paginators := make(map[string]*Paginator, len(c))
+	for name, pg := range c {
+		paginators[name] = pg.Parse(link)
+	}
+
+	return paginators
+}
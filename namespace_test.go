@@ -0,0 +1,87 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/zheeeng/pagination"
+	"github.com/zheeeng/pagination/filters"
+)
+
+func TestCollections(t *testing.T) {
+	collections := pagination.NewCollections(map[string]pagination.PaginatorConfiguration{
+		"orders": {
+			PageSize: 5,
+			Sort:     &pagination.SortConfiguration{Fields: []string{"id"}},
+		},
+		"tickets": {
+			PageSize: 3,
+			Filter: &pagination.FilterConfiguration{
+				Brackets: true,
+				Schema:   filters.Schema{"id": {Type: filters.Int}},
+			},
+		},
+	})
+
+	link := "api.example.com/dashboard?user=jk&orders.page=2&orders.sort=-id&tickets.page_size=2&tickets.id[gt]=3&page=9"
+	paginators := collections.Parse(link)
+
+	tests := []struct {
+		testName  string
+		namespace string
+		page      int64
+		pageSize  int64
+		next      string
+		filter    string
+	}{
+		{
+			"orders",
+			"orders",
+			2,
+			5,
+			"api.example.com/dashboard?orders.page=3&orders.page_size=5&orders.sort=-id&page=9&tickets.id%5Bgt%5D=3&tickets.page_size=2&user=jk",
+			"",
+		},
+		{
+			"tickets",
+			"tickets",
+			1,
+			2,
+			"api.example.com/dashboard?orders.page=2&orders.sort=-id&page=9&tickets.filter=id%3Dgt%3D3&tickets.page=2&tickets.page_size=2&user=jk",
+			"id=gt=3",
+		},
+	}
+
+	for i, test := range tests {
+		pgt := paginators[test.namespace]
+		if pgt == nil {
+			t.Fatalf("%d. [%s] missing paginator", i, test.testName)
+		}
+		if err := pgt.Err(); err != nil {
+			t.Errorf("%d. [%s] error: %v", i, test.testName, err)
+		}
+		if pgt.Namespace() != test.namespace {
+			t.Errorf("%d. [%s] namespace: got %s, want %s", i, test.testName, pgt.Namespace(), test.namespace)
+		}
+
+		paginated := pgt.Wrap(TrunctableBooks(books), total)
+		if paginated.Pagination.Page != test.page || paginated.Pagination.PageSize != test.pageSize {
+			t.Errorf("%d. [%s] page: got %d/%d, want %d/%d", i, test.testName,
+				paginated.Pagination.Page, paginated.Pagination.PageSize, test.page, test.pageSize)
+		}
+		if paginated.Pagination.Next != test.next {
+			t.Errorf("%d. [%s] next: got %s, want %s", i, test.testName, paginated.Pagination.Next, test.next)
+		}
+
+		filter := ""
+		if pgt.Filter() != nil {
+			filter = pgt.Filter().String()
+		}
+		if filter != test.filter {
+			t.Errorf("%d. [%s] filter: got %s, want %s", i, test.testName, filter, test.filter)
+		}
+	}
+
+	if sort := paginators["orders"].Sort().String(); sort != "-id" {
+		t.Errorf("orders sort: got %s, want -id", sort)
+	}
+}
@@ -0,0 +1,192 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// NestedConfiguration paginates a sub-collection embedded in each wrapped item:
+//
+// -- Field: the JSON field of the item the paginated sub-collection is put in, e.g. "books".
+// It is also the namespace of the sub-collection parameters in the parent link, e.g. "books.page_size=3"
+//
+// -- Template: the link of the sub-collection, "{name}" is replaced by the JSON field of the item, e.g. "/authors/{id}/books"
+//
+// -- Configuration: the pagination configuration of the sub-collection
+//
+// -- Load: returns the page of the sub-collection and its total, the child Paginator tells the range to load
+//
+// -- Truncate: false, whether Load returns the whole sub-collection to be truncated by the child Paginator
+type NestedConfiguration struct {
+	Field         string
+	Template      string
+	Configuration PaginatorConfiguration
+	Load          func(item interface{}, child *Paginator) (items Truncatable, total int64, err error)
+	Truncate      bool
+}
+
+type nested struct {
+	NestedConfiguration
+	pagination Pagination
+}
+
+// Nest attaches the sub-collections to the Paginator, they are paginated for each item when the items are wrapped
+func (p *Paginator) Nest(cfgs ...NestedConfiguration) *Paginator {
+	for _, cfg := range cfgs {
+		p.nested = append(p.nested, nested{cfg, NewPagination(cfg.Configuration)})
+	}
+
+	return p
+}
+
+// sliceValue returns the slice underlying the items
+func sliceValue(items Truncatable) (reflect.Value, bool) {
+	if s, ok := items.(truncatableSlice); ok {
+		return s.value, true
+	}
+
+	value := reflect.ValueOf(items)
+	return value, value.Kind() == reflect.Slice
+}
+
+// nest paginates the sub-collections of each item into Objects, it returns the items as they are if nothing is nested
+func (p *Paginator) nest(items Truncatable) (Truncatable, error) {
+	if len(p.nested) == 0 {
+		return items, nil
+	}
+
+	value, ok := sliceValue(items)
+	if !ok {
+		return items, fmt.Errorf("pagination: nested pagination expects Slice kind items, got %T", items)
+	}
+
+	objects := make(Objects, value.Len())
+	for i := range objects {
+		object, err := p.nestItem(value.Index(i).Interface())
+		if err != nil {
+			return items, err
+		}
+		objects[i] = object
+	}
+
+	return objects, nil
+}
+
+func (p *Paginator) nestItem(item interface{}) (json.RawMessage, error) {
+	object, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(object))
+	dec.UseNumber()
+	if err := dec.Decode(&variables); err != nil {
+		return nil, fmt.Errorf("pagination: nested pagination expects items encoded to JSON objects: %v", err)
+	}
+
+	for _, n := range p.nested {
+		child := n.pagination.Parse(p.nestedLink(n, variables))
+
+		childItems, total, err := n.Load(item, child)
+		if err != nil {
+			return nil, err
+		}
+
+		var paginated Paginated
+		if n.Truncate {
+			paginated, err = child.TryWrapWithTruncate(childItems, total)
+		} else {
+			paginated, err = child.TryWrap(childItems, total)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := json.Marshal(paginated)
+		if err != nil {
+			return nil, err
+		}
+		if object, err = setJSONField(object, n.Field, encoded); err != nil {
+			return nil, err
+		}
+	}
+
+	return object, nil
+}
+
+// nestedLink expands the template by the item variables and appends the sub-collection parameters of the parent link
+func (p *Paginator) nestedLink(n nested, variables map[string]interface{}) string {
+	link := n.Template
+	for name, value := range variables {
+		switch value.(type) {
+		case string, json.Number, bool:
+			link = strings.Replace(link, "{"+name+"}", url.PathEscape(fmt.Sprint(value)), -1)
+		}
+	}
+
+	prefix := n.Field + "."
+	query := url.Values{}
+	for key, values := range p.queries.Query {
+		if strings.HasPrefix(key, prefix) {
+			query[key[len(prefix):]] = values
+		}
+	}
+	if len(query) == 0 {
+		return link
+	}
+
+	return link + "?" + query.Encode()
+}
+
+// setJSONField sets the field of the JSON object, the other fields keep their order
+func setJSONField(object []byte, field string, value json.RawMessage) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	encodedField, _ := json.Marshal(field)
+
+	buf := bytes.NewBufferString("{")
+	replaced := false
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if key == field {
+			raw, replaced = value, true
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(raw)
+	}
+
+	if !replaced {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(encodedField)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,104 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+type Author struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Books []Book `json:"books,omitempty"`
+}
+
+type TruncatableAuthors []Author
+
+func (ta TruncatableAuthors) Slice(startIndex, endIndex int) pagination.Truncatable {
+	return ta[startIndex:endIndex]
+}
+func (ta TruncatableAuthors) Len() int {
+	return len(ta)
+}
+
+func TestNest(t *testing.T) {
+	authors := TruncatableAuthors{{1, "jk", books[:5]}, {2, "tolkien", books[:2]}, {3, "orwell", nil}}
+
+	loadBooks := func(item interface{}, child *pagination.Paginator) (pagination.Truncatable, int64, error) {
+		author := item.(Author)
+		return TrunctableBooks(author.Books), int64(len(author.Books)), nil
+	}
+
+	pgt := pagination.DefaultPagination().Parse("api.example.com/authors?page_size=2&books.page_size=2&books.page=2")
+	pgt.Nest(pagination.NestedConfiguration{
+		Field:         "books",
+		Template:      "api.example.com/authors/{id}/books",
+		Configuration: pagination.PaginatorConfiguration{Envelope: pagination.EnvelopeDRF},
+		Load:          loadBooks,
+		Truncate:      true,
+	})
+
+	paginated, err := pgt.TryWrapWithTruncate(authors, int64(len(authors)))
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+
+	result, err := json.Marshal(paginated.Result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	want := `[` +
+		`{"id":1,"name":"jk","books":{"count":5,` +
+		`"next":"api.example.com/authors/1/books?page=3\u0026page_size=2",` +
+		`"previous":"api.example.com/authors/1/books?page=1\u0026page_size=2",` +
+		`"results":[{"id":2,"author":"jk","name":"book"},{"id":3,"author":"jk","name":"book"}]}},` +
+		`{"id":2,"name":"tolkien","books":{"count":2,"next":null,` +
+		`"previous":"api.example.com/authors/2/books?page=1\u0026page_size=2","results":[]}}` +
+		`]`
+	if string(result) != want {
+		t.Errorf("nested result:\ngot  %s\nwant %s", result, want)
+	}
+	if objects, ok := paginated.Result.(pagination.Objects); !ok || len(objects) != 2 {
+		t.Errorf("nested result: got %T, want Objects of 2 authors", paginated.Result)
+	}
+
+	if next := paginated.Pagination.Next; next != "api.example.com/authors?books.page=2&books.page_size=2&page=2&page_size=2" {
+		t.Errorf("parent next: got %s", next)
+	}
+
+	pgt = pagination.DefaultPagination().Parse("api.example.com/authors?page=2&page_size=2")
+	pgt.Nest(pagination.NestedConfiguration{
+		Field:    "shelf",
+		Template: "/authors/{id}/books",
+		Load:     loadBooks,
+	})
+
+	paginated, err = pgt.TryWrapWithTruncate(authors, int64(len(authors)))
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	result, _ = json.Marshal(paginated.Result)
+	want = `[{"id":3,"name":"orwell","shelf":{"pagination":{"page":1,"page_size":30,"total":0,` +
+		`"first":"/authors/3/books?page=1\u0026page_size=30","last":"",` +
+		`"prev":"/authors/3/books?page=1\u0026page_size=30","next":"/authors/3/books?page=2\u0026page_size=30",` +
+		`"query":{"page":["1"],"page_size":["30"]}},"result":null}}]`
+	if string(result) != want {
+		t.Errorf("appended field:\ngot  %s\nwant %s", result, want)
+	}
+
+	failure := errors.New("load failure")
+	pgt = pagination.DefaultPagination().Parse("api.example.com/authors")
+	pgt.Nest(pagination.NestedConfiguration{
+		Field:    "books",
+		Template: "/authors/{id}/books",
+		Load: func(interface{}, *pagination.Paginator) (pagination.Truncatable, int64, error) {
+			return nil, 0, failure
+		},
+	})
+	if _, err := pgt.TryWrap(authors, int64(len(authors))); err != failure {
+		t.Errorf("load failure: got %v, want %v", err, failure)
+	}
+}
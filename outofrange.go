@@ -0,0 +1,72 @@
+package pagination
+
+import "fmt"
+
+// OutOfRangePolicy defines how a page beyond the last page is served, it is evaluated once the total is known
+type OutOfRangePolicy int
+
+// Out-of-range policies
+const (
+	// OutOfRangeEmpty serves an empty page and reports the requested page
+	OutOfRangeEmpty OutOfRangePolicy = iota
+	// OutOfRangeClamp serves the last page and reports it as the served page
+	OutOfRangeClamp
+	// OutOfRangeRedirect serves an empty page and reports a *PageRedirectError pointing at the last page
+	OutOfRangeRedirect
+	// OutOfRangeNotFound serves an empty page and reports a *PageNotFoundError
+	OutOfRangeNotFound
+)
+
+// PageRedirectError is reported by the OutOfRangeRedirect policy, Location is the link to the last page
+type PageRedirectError struct {
+	Page     int64
+	Last     int64
+	Location string
+}
+
+func (e *PageRedirectError) Error() string {
+	return fmt.Sprintf("pagination: page %d is beyond the last page %d, redirect to %s", e.Page, e.Last, e.Location)
+}
+
+// PageNotFoundError is reported by the OutOfRangeNotFound policy
+type PageNotFoundError struct {
+	Page int64
+	Last int64
+}
+
+func (e *PageNotFoundError) Error() string {
+	return fmt.Sprintf("pagination: page %d is beyond the last page %d", e.Page, e.Last)
+}
+
+// wrapFields applies the out-of-range policy and builds the pagination fields, the redirect link is kept in the fields
+func (p *Paginator) wrapFields(total int64) (*PageFields, error) {
+	err := p.applyOutOfRange(total)
+	fields := p.buildFields()
+
+	if redirect, ok := err.(*PageRedirectError); ok {
+		fields.Redirect = redirect.Location
+	}
+
+	return fields, err
+}
+
+// applyOutOfRange sets the total to pager and applies the out-of-range policy.
+// An empty list has no last page, so no page of it is out of range.
+func (p *Paginator) applyOutOfRange(total int64) error {
+	nav := p.pager.SetTotal(total).GetNavigation()
+
+	if nav.Total <= 0 || nav.Page <= nav.Last {
+		return nil
+	}
+
+	switch p.outOfRange {
+	case OutOfRangeClamp:
+		p.pager.SetPageInfo(nav.Last, nav.PageSize)
+	case OutOfRangeRedirect:
+		return &PageRedirectError{nav.Page, nav.Last, p.pageLink(nav.Last)}
+	case OutOfRangeNotFound:
+		return &PageNotFoundError{nav.Page, nav.Last}
+	}
+
+	return nil
+}
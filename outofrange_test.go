@@ -0,0 +1,63 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func TestOutOfRange(t *testing.T) {
+	link := "api.example.com/books?author=jk&page=50&page_size=5"
+	lastLink := "api.example.com/books?author=jk&page=4&page_size=5"
+
+	tests := []struct {
+		testName string
+		policy   pagination.OutOfRangePolicy
+		page     int64
+		items    int
+		prev     string
+		redirect string
+	}{
+		{"empty", pagination.OutOfRangeEmpty, 50, 0, lastLink, ""},
+		{"clamp", pagination.OutOfRangeClamp, 4, 5, "api.example.com/books?author=jk&page=3&page_size=5", ""},
+		{"redirect", pagination.OutOfRangeRedirect, 50, 0, lastLink, lastLink},
+		{"not found", pagination.OutOfRangeNotFound, 50, 0, lastLink, ""},
+	}
+
+	for i, test := range tests {
+		pg := pagination.NewPagination(pagination.PaginatorConfiguration{OutOfRange: test.policy})
+		paginated, err := pg.Parse(link).TryWrapWithTruncate(TrunctableBooks(books), total)
+
+		if paginated.Pagination.Page != test.page || paginated.Result.Len() != test.items {
+			t.Errorf("%d. [%s] page: got %d with %d items, want %d with %d items",
+				i, test.testName, paginated.Pagination.Page, paginated.Result.Len(), test.page, test.items)
+		}
+		if paginated.Pagination.Prev != test.prev {
+			t.Errorf("%d. [%s] prev: got %s, want %s", i, test.testName, paginated.Pagination.Prev, test.prev)
+		}
+		if redirect := pg.Parse(link).Wrap(TrunctableBooks(nil), total).Pagination.Redirect; redirect != test.redirect {
+			t.Errorf("%d. [%s] redirect of Wrap: got %q, want %q", i, test.testName, redirect, test.redirect)
+		}
+
+		switch e := err.(type) {
+		case nil:
+			if test.policy == pagination.OutOfRangeRedirect || test.policy == pagination.OutOfRangeNotFound {
+				t.Errorf("%d. [%s] expects an error", i, test.testName)
+			}
+		case *pagination.PageRedirectError:
+			if test.policy != pagination.OutOfRangeRedirect || e.Location != lastLink || e.Page != 50 || e.Last != 4 {
+				t.Errorf("%d. [%s] unexpected error: %#v", i, test.testName, e)
+			}
+		case *pagination.PageNotFoundError:
+			if test.policy != pagination.OutOfRangeNotFound || e.Page != 50 || e.Last != 4 {
+				t.Errorf("%d. [%s] unexpected error: %#v", i, test.testName, e)
+			}
+		default:
+			t.Errorf("%d. [%s] unexpected error: %v", i, test.testName, err)
+		}
+
+		if _, err := pg.Parse(requestURI).TryWrap(TrunctableBooks(books[5:10]), total); err != nil {
+			t.Errorf("%d. [%s] in range page: unexpected error: %v", i, test.testName, err)
+		}
+	}
+}
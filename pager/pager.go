@@ -1,29 +1,36 @@
 package pager
 
 import (
+	"errors"
 	"math"
 )
 
+// ErrOverflow is returned when the offsets of a page overflow int64
+var ErrOverflow = errors.New("pager: offset overflows int64")
+
 // Pager provides basic calculations
-// if total is greater than, page is restrict to a range between 0 and maxpage
+// if total is greater than, page is restrict to a range between 0 and maxpage.
+// The page is stored one-based, base only shifts the page numbers going in and out.
+// All the arithmetic saturates at the int64 bounds instead of wrapping around.
 type Pager struct {
-	total    int
-	page     int
-	pageSize int
+	total    int64
+	page     int64
+	pageSize int64
+	base     int64
 }
 
-// Navigation defines pager infomation
+// Navigation defines pager infomation, Total and Last are 0 when the total is unknown whatever the base is
 type Navigation struct {
-	Total    int
-	Page     int
-	PageSize int
-	First    int
-	Last     int
-	Prev     int
-	Next     int
+	Total    int64
+	Page     int64
+	PageSize int64
+	First    int64
+	Last     int64
+	Prev     int64
+	Next     int64
 }
 
-func compact(min, max, value int) int {
+func compact(min, max, value int64) int64 {
 	if min > max {
 		min, max = max, min
 	}
@@ -36,7 +43,28 @@ func compact(min, max, value int) int {
 	return value
 }
 
-func divCeil(a, b int) int {
+// addClamped returns a + b, saturated at the int64 bounds
+func addClamped(a, b int64) int64 {
+	if b > 0 && a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	if b < 0 && a < math.MinInt64-b {
+		return math.MinInt64
+	}
+
+	return a + b
+}
+
+// mulClamped returns a * b of non-negative operands, saturated at math.MaxInt64, ok is false if it overflows
+func mulClamped(a, b int64) (product int64, ok bool) {
+	if a != 0 && b > math.MaxInt64/a {
+		return math.MaxInt64, false
+	}
+
+	return a * b, true
+}
+
+func divCeil(a, b int64) int64 {
 	d := a / b
 	if a%b > 0 {
 		return d + 1
@@ -46,44 +74,77 @@ func divCeil(a, b int) int {
 }
 
 //NewPager returns Pager instance
-func NewPager(page, pageSize int) *Pager {
-	return &Pager{0, compact(1, math.MaxInt32, page), compact(1, math.MaxInt32, pageSize)}
+func NewPager(page, pageSize int64) *Pager {
+	return NewPagerWithBase(page, pageSize, 1)
+}
+
+// NewPagerWithBase returns Pager instance whose page numbers start from base, e.g. 0 for zero-based page numbering
+func NewPagerWithBase(page, pageSize, base int64) *Pager {
+	p := &Pager{base: base}
+	return p.SetPageInfo(page, pageSize)
+}
+
+// fromBase converts a page number in base to the one-based page
+func (p *Pager) fromBase(page int64) int64 {
+	return compact(1, math.MaxInt64, addClamped(addClamped(page, -p.base), 1))
+}
+
+// toBase converts a one-based page to the page number in base
+func (p *Pager) toBase(page int64) int64 {
+	return addClamped(page, p.base-1)
 }
 
 // getDefaultNavigation returns navigation info when missing total value
 func (p *Pager) getDefaultNavigation() Navigation {
 	return Navigation{
 		Total:    0,
-		Page:     p.page,
+		Page:     p.toBase(p.page),
 		PageSize: p.pageSize,
-		First:    1,
+		First:    p.toBase(1),
 		Last:     0,
-		Prev:     compact(1, math.MaxInt32, p.page-1),
-		Next:     compact(1, math.MaxInt32, p.page+1),
+		Prev:     p.toBase(compact(1, math.MaxInt64, p.page-1)),
+		Next:     p.toBase(compact(1, math.MaxInt64, addClamped(p.page, 1))),
 	}
 }
 
 // SetTotal sets total value to pager
-func (p *Pager) SetTotal(total int) *Pager {
+func (p *Pager) SetTotal(total int64) *Pager {
 	p.total = total
 	return p
 }
 
-// SetPageInfo resets page and pageSize to pager
-func (p *Pager) SetPageInfo(page, pageSize int) *Pager {
-	p.page = compact(1, math.MaxInt32, page)
-	p.pageSize = compact(1, math.MaxInt32, pageSize)
+// SetPageInfo resets page and pageSize to pager, page is numbered from the pager base
+func (p *Pager) SetPageInfo(page, pageSize int64) *Pager {
+	p.page = p.fromBase(page)
+	p.pageSize = compact(1, math.MaxInt64, pageSize)
 	return p
 }
 
+// SetRange resets page and pageSize by an inclusive range of zero-based item indexes,
+// pageSize is the range length and page is the one containing the first item
+func (p *Pager) SetRange(first, last int64) *Pager {
+	first = compact(0, math.MaxInt64, first)
+	pageSize := compact(1, math.MaxInt64, addClamped(addClamped(last, -first), 1))
+	return p.SetPageInfo(p.toBase(first/pageSize+1), pageSize)
+}
+
 // ClonePager returns a fresh pager with specified page and pageSize
-func (p *Pager) ClonePager(page, pageSize int) *Pager {
-	return &Pager{p.total, compact(1, math.MaxInt32, page), compact(1, math.MaxInt32, pageSize)}
+func (p *Pager) ClonePager(page, pageSize int64) *Pager {
+	return NewPagerWithBase(page, pageSize, p.base).SetTotal(p.total)
 }
 
 // ClonePagerWithCursor returns a fresh pager with specified cursor value and pageSize
-func (p *Pager) ClonePagerWithCursor(cursor, pageSize int) *Pager {
-	return p.ClonePager(divCeil(cursor, pageSize), pageSize)
+func (p *Pager) ClonePagerWithCursor(cursor, pageSize int64) *Pager {
+	return p.ClonePager(p.toBase(divCeil(cursor, pageSize)), pageSize)
+}
+
+// LocateIndex returns the page containing the zero-based item index and the item position within that page
+func (p *Pager) LocateIndex(index int64) (page, position int64) {
+	if index < 0 {
+		index = 0
+	}
+
+	return p.toBase(index/p.pageSize + 1), index % p.pageSize
 }
 
 // GetNavigation returns navigation info
@@ -96,29 +157,51 @@ func (p *Pager) GetNavigation() Navigation {
 
 	return Navigation{
 		Total:    p.total,
-		Page:     p.page,
+		Page:     p.toBase(p.page),
 		PageSize: p.pageSize,
-		First:    1,
-		Last:     last,
-		Prev:     compact(1, last, p.page-1),
-		Next:     compact(1, last, p.page+1),
+		First:    p.toBase(1),
+		Last:     p.toBase(last),
+		Prev:     p.toBase(compact(1, last, p.page-1)),
+		Next:     p.toBase(compact(1, last, addClamped(p.page, 1))),
 	}
 }
 
-// GetRange returns the start and end offset values
-func (p *Pager) GetRange() (start, end int) {
-	offset, length := p.GetOffsetRange()
+// GetRange returns the start and end offset values, they are clamped to math.MaxInt64 on overflow
+func (p *Pager) GetRange() (start, end int64) {
+	start, end, _ = p.GetCheckedRange()
+
+	return
+}
+
+// GetCheckedRange does the same thing with GetRange, and it returns ErrOverflow if the offsets overflow
+func (p *Pager) GetCheckedRange() (start, end int64, err error) {
+	offset, length, err := p.GetCheckedOffsetRange()
 	start = offset
-	end = offset + length
+	end = addClamped(offset, length)
+
+	if err == nil && end-length != offset {
+		err = ErrOverflow
+	}
+
+	return
+}
+
+// GetOffsetRange returns start and end offsets of items, the offset is clamped to math.MaxInt64 on overflow
+func (p *Pager) GetOffsetRange() (offset, length int64) {
+	offset, length, _ = p.GetCheckedOffsetRange()
 
 	return
 }
 
-// GetOffsetRange returns start and end offsets of items
-func (p *Pager) GetOffsetRange() (offset, length int) {
-	offset = (p.page - 1) * p.pageSize
+// GetCheckedOffsetRange does the same thing with GetOffsetRange, and it returns ErrOverflow if the offset overflows
+func (p *Pager) GetCheckedOffsetRange() (offset, length int64, err error) {
+	offset, ok := mulClamped(p.page-1, p.pageSize)
 	length = p.pageSize
 
+	if !ok {
+		err = ErrOverflow
+	}
+
 	if p.total > 0 {
 		offset = compact(0, p.total, offset)
 		length = compact(0, p.total-offset, length)
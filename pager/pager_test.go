@@ -2,16 +2,17 @@ package pager
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 )
 
 func TestCompact(t *testing.T) {
 	tests := []struct {
-		min    int
-		max    int
-		value  int
-		expect int
+		min    int64
+		max    int64
+		value  int64
+		expect int64
 	}{
 		{1, 10, 5, 5},
 		{1, 10, 0, 1},
@@ -37,9 +38,9 @@ func TestCompact(t *testing.T) {
 
 func TestDivCeil(t *testing.T) {
 	tests := []struct {
-		a      int
-		b      int
-		expect int
+		a      int64
+		b      int64
+		expect int64
 	}{
 		{51, 10, 6},
 		{50, 10, 5},
@@ -59,8 +60,8 @@ func TestDivCeil(t *testing.T) {
 func TestPager(t *testing.T) {
 	tests := []struct {
 		caseName              string
-		page, pageSize, total int
-		start, end            int
+		page, pageSize, total int64
+		start, end            int64
 		navigation            Navigation
 	}{
 		{
@@ -133,8 +134,8 @@ func TestPager(t *testing.T) {
 
 func TestSetPageInfoAndClonePager(t *testing.T) {
 	tests := []struct {
-		fromPage, fromPageSize, fromTotal int
-		toPage, toPageSize, toTotal       int
+		fromPage, fromPageSize, fromTotal int64
+		toPage, toPageSize, toTotal       int64
 	}{
 		{5, 10, 0, 5, 10, -1},
 		{5, 10, -1, 0, 10, 0},
@@ -152,7 +153,7 @@ func TestSetPageInfoAndClonePager(t *testing.T) {
 
 		clonedPager := pager.ClonePager(test.toPage, test.toPageSize)
 		clonedPager2 := pager.ClonePagerWithCursor(
-			test.toPage*test.toPageSize-rand.Intn(test.toPageSize),
+			test.toPage*test.toPageSize-rand.Int63n(test.toPageSize),
 			test.toPageSize,
 		)
 
@@ -176,3 +177,192 @@ func TestSetPageInfoAndClonePager(t *testing.T) {
 	}
 
 }
+
+func TestLocateIndex(t *testing.T) {
+	tests := []struct {
+		index, pageSize int64
+		page, position  int64
+	}{
+		{0, 10, 1, 0},
+		{9, 10, 1, 9},
+		{10, 10, 2, 0},
+		{47, 10, 5, 7},
+		{-1, 10, 1, 0},
+	}
+
+	for i, test := range tests {
+		page, position := NewPager(1, test.pageSize).LocateIndex(test.index)
+
+		if page != test.page || position != test.position {
+			t.Errorf("%d. [LocateIndex failed], index: %d, pageSize: %d, expects (%d, %d), got (%d, %d)",
+				i, test.index, test.pageSize, test.page, test.position, page, position,
+			)
+		}
+	}
+}
+
+func TestSetRange(t *testing.T) {
+	tests := []struct {
+		first, last    int64
+		page, pageSize int64
+	}{
+		{0, 24, 1, 25},
+		{25, 49, 2, 25},
+		{10, 34, 1, 25},
+		{5, 5, 6, 1},
+		{10, 0, 11, 1},
+	}
+
+	for i, test := range tests {
+		navigation := NewPager(1, 10).SetRange(test.first, test.last).GetNavigation()
+
+		if navigation.Page != test.page || navigation.PageSize != test.pageSize {
+			t.Errorf("%d. [SetRange failed], range: %d-%d, expects (%d, %d), got (%d, %d)",
+				i, test.first, test.last, test.page, test.pageSize, navigation.Page, navigation.PageSize,
+			)
+		}
+	}
+}
+
+func TestPagerWithBase(t *testing.T) {
+	tests := []struct {
+		caseName              string
+		page, pageSize, total int64
+		start, end            int64
+		navigation            Navigation
+	}{
+		{
+			"total is zero value",
+			4, 10, 0,
+			40, 50,
+			Navigation{0, 4, 10, 0, 0, 3, 5},
+		},
+		{
+			"first page",
+			0, 10, 100,
+			0, 10,
+			Navigation{100, 0, 10, 0, 9, 0, 1},
+		},
+		{
+			"page below the base",
+			-1, 10, 100,
+			0, 10,
+			Navigation{100, 0, 10, 0, 9, 0, 1},
+		},
+		{
+			"total is below to upper bound",
+			4, 10, 49,
+			40, 49,
+			Navigation{49, 4, 10, 0, 4, 3, 4},
+		},
+	}
+
+	for i, test := range tests {
+		pager := NewPagerWithBase(test.page, test.pageSize, 0).SetTotal(test.total)
+
+		navigation := pager.GetNavigation()
+		start, end := pager.GetRange()
+
+		if navigation != test.navigation {
+			t.Errorf("%d. [%s] navigation: expects %v, got %v", i, test.caseName, test.navigation, navigation)
+		}
+		if start != test.start || end != test.end {
+			t.Errorf("%d. [%s] range: expects (%d, %d), got (%d, %d)", i, test.caseName, test.start, test.end, start, end)
+		}
+	}
+
+	pager := NewPagerWithBase(0, 10, 0)
+	if page, position := pager.LocateIndex(25); page != 2 || position != 5 {
+		t.Errorf("[LocateIndex with base] expects (2, 5), got (%d, %d)", page, position)
+	}
+	if navigation := pager.ClonePagerWithCursor(25, 10).GetNavigation(); navigation.Page != 2 {
+		t.Errorf("[ClonePagerWithCursor with base] expects page 2, got %d", navigation.Page)
+	}
+	if navigation := pager.SetRange(20, 29).GetNavigation(); navigation.Page != 2 || navigation.PageSize != 10 {
+		t.Errorf("[SetRange with base] expects page 2, got %v", navigation)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		caseName       string
+		page, pageSize int64
+		total          int64
+		offset, length int64
+		end            int64
+		offsetErr      error
+		rangeErr       error
+	}{
+		{
+			"beyond int32",
+			3000001, 1000, 5000000000,
+			3000000000, 1000, 3000001000,
+			nil, nil,
+		},
+		{
+			"largest page without overflow",
+			math.MaxInt64/10 + 1, 10, 0,
+			math.MaxInt64 / 10 * 10, 10, math.MaxInt64,
+			nil, ErrOverflow,
+		},
+		{
+			"offset overflows",
+			math.MaxInt64/10 + 2, 10, 0,
+			math.MaxInt64, 10, math.MaxInt64,
+			ErrOverflow, ErrOverflow,
+		},
+		{
+			"maximum page and page size",
+			math.MaxInt64, math.MaxInt64, 0,
+			math.MaxInt64, math.MaxInt64, math.MaxInt64,
+			ErrOverflow, ErrOverflow,
+		},
+		{
+			"offset overflows with total",
+			math.MaxInt64, 10, 5000000000,
+			5000000000, 0, 5000000000,
+			ErrOverflow, ErrOverflow,
+		},
+	}
+
+	for i, test := range tests {
+		pager := NewPager(test.page, test.pageSize).SetTotal(test.total)
+
+		offset, length, offsetErr := pager.GetCheckedOffsetRange()
+		start, end, rangeErr := pager.GetCheckedRange()
+
+		if offset != test.offset || length != test.length || offsetErr != test.offsetErr {
+			t.Errorf("%d. [%s] offset range: expects (%d, %d, %v), got (%d, %d, %v)",
+				i, test.caseName, test.offset, test.length, test.offsetErr, offset, length, offsetErr)
+		}
+		if start != test.offset || end != test.end || rangeErr != test.rangeErr {
+			t.Errorf("%d. [%s] range: expects (%d, %d, %v), got (%d, %d, %v)",
+				i, test.caseName, test.offset, test.end, test.rangeErr, start, end, rangeErr)
+		}
+		if uncheckedOffset, uncheckedLength := pager.GetOffsetRange(); uncheckedOffset != offset || uncheckedLength != length {
+			t.Errorf("%d. [%s] unchecked offset range differs: (%d, %d)", i, test.caseName, uncheckedOffset, uncheckedLength)
+		}
+	}
+}
+
+func TestNavigationBoundaries(t *testing.T) {
+	navigation := NewPager(math.MaxInt64, 1).GetNavigation()
+	if navigation.Page != math.MaxInt64 || navigation.Next != math.MaxInt64 || navigation.Prev != math.MaxInt64-1 {
+		t.Errorf("[maximum page] got %v", navigation)
+	}
+
+	navigation = NewPager(2, 1000).SetTotal(math.MaxInt64).GetNavigation()
+	if navigation.Last != math.MaxInt64/1000+1 || navigation.Next != 3 {
+		t.Errorf("[maximum total] got %v", navigation)
+	}
+
+	navigation = NewPagerWithBase(math.MaxInt64, 1, 0).GetNavigation()
+	if navigation.Page != math.MaxInt64-1 || navigation.Next != math.MaxInt64-1 {
+		t.Errorf("[maximum page with base] got %v", navigation)
+	}
+
+	navigation = NewPager(math.MinInt64, math.MinInt64).SetRange(math.MinInt64, math.MaxInt64).GetNavigation()
+	if navigation.Page != 1 || navigation.PageSize != math.MaxInt64 {
+		t.Errorf("[extreme range] got %v", navigation)
+	}
+}
@@ -58,8 +58,10 @@ Get details: https://github.com/zheeeng/pagination
 package pagination
 
 import (
+	"github.com/zheeeng/pagination/filters"
 	"github.com/zheeeng/pagination/pager"
 	"github.com/zheeeng/pagination/queries"
+	"github.com/zheeeng/pagination/uritemplate"
 )
 
 type runInContext func(p *Paginator) Truncatable
@@ -71,47 +73,252 @@ type Pagination interface {
 
 const defaultPageSize = 30
 
+const defaultPageParam = "page"
+
+const defaultPageSizeParam = "page_size"
+
+const defaultSortParam = "sort"
+
+const defaultFilterParam = "filter"
+
+const defaultFieldsetParam = "fields"
+
+const defaultRangeUnit = "items"
+
 // PaginatorConfiguration defines the default pagination parameters. By default:
 //
 // -- PageSize: 30
+//
+// -- MaxPageSize: 0, the page size is unlimited
+//
+// -- PageParam: "page"
+//
+// -- PageSizeParam: "page_size"
+//
+// -- Envelope: EnvelopeDefault, the shape Paginated is encoded to JSON in
+//
+// -- PathPattern: empty, the tail of the path carrying the page and page size instead of the query,
+// e.g. "/page/{page}" reads "/books/page/3", and "/p/{page}/size/{page_size}" reads "/books/p/3/size/50"
+//
+// -- Namespace: empty, the prefix of all the parameter names, e.g. "orders" reads "orders.page" and "orders.sort",
+// it lets several collections be paginated independently by one link
+//
+// -- Sort: nil, the sort parameter is passed through untouched
+//
+// -- Filter: nil, the filter parameters are passed through untouched
+//
+// -- Fieldset: nil, the fields parameter is passed through untouched and the items are kept whole
+//
+// -- RangeUnit: "items", the unit of Range and Content-Range headers
+//
+// -- ZeroBasedPage: false, whether the first page is numbered 0 instead of 1
+//
+// -- OutOfRange: OutOfRangeEmpty, how a page beyond the last page is served
+//
+// -- Links: the navigation links keep the origin and path of the parsed link
+//
+// -- PreserveQueryOrder: false, whether the navigation links keep the original order and repeated values of the query,
+// by default the query is encoded in key order
 type PaginatorConfiguration struct {
-	PageSize int
+	PageSize           int64                  `json:"page_size,omitempty"`
+	MaxPageSize        int64                  `json:"max_page_size,omitempty"`
+	PageParam          string                 `json:"page_param,omitempty"`
+	PageSizeParam      string                 `json:"page_size_param,omitempty"`
+	Envelope           Envelope               `json:"envelope,omitempty"`
+	Namespace          string                 `json:"namespace,omitempty"`
+	PathPattern        string                 `json:"path_pattern,omitempty"`
+	Sort               *SortConfiguration     `json:"sort,omitempty"`
+	Filter             *FilterConfiguration   `json:"filter,omitempty"`
+	Fieldset           *FieldsetConfiguration `json:"fieldset,omitempty"`
+	RangeUnit          string                 `json:"range_unit,omitempty"`
+	ZeroBasedPage      bool                   `json:"zero_based_page,omitempty"`
+	OutOfRange         OutOfRangePolicy       `json:"out_of_range,omitempty"`
+	Links              LinkConfiguration      `json:"links,omitempty"`
+	PreserveQueryOrder bool                   `json:"preserve_query_order,omitempty"`
+}
+
+// SortConfiguration defines how the sort parameter is parsed. By default:
+//
+// -- Param: "sort"
+//
+// -- Fields: empty, any field is sortable
+//
+// -- Default: empty, used when the link doesn't provide a valid sort expression, e.g. "-created_at"
+type SortConfiguration struct {
+	Param   string   `json:"param,omitempty"`
+	Fields  []string `json:"fields,omitempty"`
+	Default string   `json:"default,omitempty"`
+}
+
+// FilterConfiguration defines how the filter parameters are parsed. By default:
+//
+// -- Param: "filter", it carries a RSQL/FIQL expression, e.g. "author==jk;year>2000"
+//
+// -- Brackets: false, whether the LHS bracket parameters are parsed, e.g. "price[gte]=10"
+//
+// -- Schema: nil, the expression is not validated and its values are kept as strings
+type FilterConfiguration struct {
+	Param    string         `json:"param,omitempty"`
+	Brackets bool           `json:"brackets,omitempty"`
+	Schema   filters.Schema `json:"schema,omitempty"`
+}
+
+// FieldsetConfiguration defines how the sparse fieldset parameter is parsed. By default:
+//
+// -- Param: "fields", it carries the selected fields of the items, e.g. "id,name"
+//
+// -- Fields: empty, any field is selectable
+//
+// -- Default: empty, used when the link doesn't select any valid field, the items are kept whole if it is empty
+type FieldsetConfiguration struct {
+	Param   string   `json:"param,omitempty"`
+	Fields  []string `json:"fields,omitempty"`
+	Default string   `json:"default,omitempty"`
 }
 
 type pagination struct {
 	paginatorConfiguration PaginatorConfiguration
+	pathPattern            pathPattern
+	pathPatternErr         error
+	template               *uritemplate.Template
+	templateErr            error
 }
 
 // DefaultPagination returns a default pagination instance
 func DefaultPagination() Pagination {
-	return &pagination{
-		paginatorConfiguration: PaginatorConfiguration{
-			PageSize: defaultPageSize,
-		},
-	}
+	return NewPagination(PaginatorConfiguration{})
 }
 
 // NewPagination create a fresh pagination instance
 func NewPagination(cfg PaginatorConfiguration) Pagination {
+	p := &pagination{
+		paginatorConfiguration: cfg.withDefaults(),
+	}
+
+	// the path pattern and the link template are parsed once, their errors are reported by each parsed Paginator
+	if cfg.PathPattern != "" {
+		p.pathPattern, p.pathPatternErr = parsePathPattern(cfg.PathPattern)
+	}
+	if cfg.Links.Template != "" {
+		p.template, p.templateErr = uritemplate.Parse(cfg.Links.Template)
+	}
+
+	return p
+}
+
+// withDefaults returns the configuration with its zero value fields set to defaults
+func (cfg PaginatorConfiguration) withDefaults() PaginatorConfiguration {
 	if cfg.PageSize == 0 {
 		cfg.PageSize = defaultPageSize
 	}
+	if cfg.MaxPageSize > 0 && cfg.PageSize > cfg.MaxPageSize {
+		cfg.PageSize = cfg.MaxPageSize
+	}
+	if cfg.PageParam == "" {
+		cfg.PageParam = defaultPageParam
+	}
+	if cfg.PageSizeParam == "" {
+		cfg.PageSizeParam = defaultPageSizeParam
+	}
+	if cfg.RangeUnit == "" {
+		cfg.RangeUnit = defaultRangeUnit
+	}
+	if cfg.Sort != nil && cfg.Sort.Param == "" {
+		sortCfg := *cfg.Sort
+		sortCfg.Param = defaultSortParam
+		cfg.Sort = &sortCfg
+	}
+	if cfg.Filter != nil && cfg.Filter.Param == "" {
+		filterCfg := *cfg.Filter
+		filterCfg.Param = defaultFilterParam
+		cfg.Filter = &filterCfg
+	}
+	if cfg.Fieldset != nil && cfg.Fieldset.Param == "" {
+		fieldsetCfg := *cfg.Fieldset
+		fieldsetCfg.Param = defaultFieldsetParam
+		cfg.Fieldset = &fieldsetCfg
+	}
 
-	return &pagination{
-		paginatorConfiguration: cfg,
+	if cfg.Namespace != "" {
+		cfg.PageParam = namespaced(cfg.Namespace, cfg.PageParam)
+		cfg.PageSizeParam = namespaced(cfg.Namespace, cfg.PageSizeParam)
+		if cfg.Sort != nil {
+			sortCfg := *cfg.Sort
+			sortCfg.Param = namespaced(cfg.Namespace, sortCfg.Param)
+			cfg.Sort = &sortCfg
+		}
+		if cfg.Filter != nil {
+			filterCfg := *cfg.Filter
+			filterCfg.Param = namespaced(cfg.Namespace, filterCfg.Param)
+			cfg.Filter = &filterCfg
+		}
+		if cfg.Fieldset != nil {
+			fieldsetCfg := *cfg.Fieldset
+			fieldsetCfg.Param = namespaced(cfg.Namespace, fieldsetCfg.Param)
+			cfg.Fieldset = &fieldsetCfg
+		}
 	}
+
+	return cfg
 }
 
 func (p *pagination) Parse(link string) *Paginator {
-	basePath, page, pageSize, queries, hasPage, hasPageSize := queries.ParseLink(link, p.paginatorConfiguration.PageSize)
+	cfg := p.paginatorConfiguration
+	params := queries.Params{Page: cfg.PageParam, PageSize: cfg.PageSizeParam}
+
+	basePath, page, pageSize, queries, hasPage, hasPageSize := queries.ParseLinkWithParams(link, cfg.PageSize, params)
+
+	if p.pathPattern != nil {
+		basePath, page, pageSize, hasPage, hasPageSize = p.pathPattern.apply(basePath, page, pageSize, hasPage, hasPageSize)
+	}
+
+	if cfg.MaxPageSize > 0 && pageSize > cfg.MaxPageSize {
+		pageSize = cfg.MaxPageSize
+	}
+
+	base := int64(1)
+	if p.paginatorConfiguration.ZeroBasedPage {
+		base = 0
+	}
+	if !hasPage {
+		page = base
+	}
 
 	pgt := &Paginator{
-		pager:           pager.NewPager(page, pageSize),
-		basePath:        basePath,
+		pager:           pager.NewPagerWithBase(page, pageSize, base),
+		basePath:        p.paginatorConfiguration.Links.rewrite(basePath),
 		queries:         queries,
 		defaultPageSize: p.paginatorConfiguration.PageSize,
 		hasPage:         hasPage,
 		hasPageSize:     hasPageSize,
+		rangeUnit:       p.paginatorConfiguration.RangeUnit,
+		outOfRange:      p.paginatorConfiguration.OutOfRange,
+		preserveOrder:   p.paginatorConfiguration.PreserveQueryOrder,
+		params:          params,
+		namespace:       p.paginatorConfiguration.Namespace,
+		envelope:        p.paginatorConfiguration.Envelope,
+		pathPattern:     p.pathPattern,
+		template:        p.template,
+	}
+
+	if p.pathPatternErr != nil {
+		pgt.errs = append(pgt.errs, p.pathPatternErr)
+	}
+
+	if p.templateErr != nil {
+		pgt.errs = append(pgt.errs, p.templateErr)
+	}
+
+	if sortCfg := p.paginatorConfiguration.Sort; sortCfg != nil {
+		pgt.parseSort(sortCfg)
+	}
+
+	if filterCfg := p.paginatorConfiguration.Filter; filterCfg != nil {
+		pgt.parseFilter(filterCfg)
+	}
+
+	if fieldsetCfg := p.paginatorConfiguration.Fieldset; fieldsetCfg != nil {
+		pgt.parseFieldset(fieldsetCfg)
 	}
 
 	return pgt
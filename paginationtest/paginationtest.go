@@ -0,0 +1,466 @@
+// Package paginationtest crawls a paginated endpoint by its navigation links and checks the pagination invariants:
+//
+// -- no item is duplicated or missing across the pages, and the items add up to the total
+//
+// -- each page but the last one is full, and the pages are numbered one after another
+//
+// -- the last page agrees with the total
+//
+// -- the prev link of the next page returns to the same page
+//
+// -- the first page has no prev link, the last page has no next link,
+// and the first and last links return the first and last crawled pages
+//
+// The responses are read by the client package, so the envelope of this library,
+// the Link headers and the other envelopes it supports are checked alike.
+package paginationtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zheeeng/pagination/client"
+)
+
+const defaultMaxPages = 1000
+
+// Invariants
+const (
+	InvariantDuplicate = "duplicate item"
+	InvariantTotal     = "total"
+	InvariantPageSize  = "page size"
+	InvariantPage      = "page number"
+	InvariantLast      = "last page"
+	InvariantPrev      = "prev of next"
+	InvariantBoundary  = "boundary link"
+	InvariantCrawl     = "crawl"
+)
+
+// Violation is an invariant broken by a page, Detail may hold several lines
+type Violation struct {
+	Invariant string
+	Page      int64
+	URL       string
+	Detail    string
+}
+
+func (v Violation) String() string {
+	detail := strings.Replace(v.Detail, "\n", "\n      ", -1)
+	return fmt.Sprintf("page %d (%s): %s: %s", v.Page, v.URL, v.Invariant, detail)
+}
+
+// Report is the result of a crawl
+type Report struct {
+	Pages      int
+	Items      int
+	Violations []Violation
+}
+
+// OK returns whether no invariant is violated
+func (r *Report) OK() bool {
+	return len(r.Violations) == 0
+}
+
+func (r *Report) String() string {
+	lines := []string{fmt.Sprintf("pagination: %d violations in %d pages of %d items", len(r.Violations), r.Pages, r.Items)}
+	for _, v := range r.Violations {
+		lines = append(lines, "    "+v.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Checker crawls a paginated endpoint. By default:
+//
+// -- Handler: nil, the handler serving the requests in-process, the links are requested by Client if it is nil
+//
+// -- Client: http.DefaultClient
+//
+// -- ID: the "id" field of the object items, or the whole item, it identifies the items across the pages
+//
+// -- MaxPages: 1000, the crawl stops with a violation beyond it
+type Checker struct {
+	Handler  http.Handler
+	Client   *http.Client
+	ID       func(item json.RawMessage) string
+	MaxPages int
+}
+
+// page is a crawled page
+type page struct {
+	url string
+	client.Navigation
+	ids []string
+}
+
+// Check crawls the handler from link, e.g. "/books?page_size=5", and reports the violations to t
+func Check(t testing.TB, h http.Handler, link string) *Report {
+	t.Helper()
+
+	report, err := Checker{Handler: h}.Check(link)
+	if err != nil {
+		t.Errorf("pagination: %v", err)
+		return report
+	}
+	if !report.OK() {
+		t.Error(report)
+	}
+
+	return report
+}
+
+// Check crawls the pages from link and checks the invariants, an error is returned if a page can't be fetched or read
+func (c Checker) Check(link string) (*Report, error) {
+	report := &Report{}
+
+	if c.Handler != nil && strings.HasPrefix(link, "/") {
+		link = "http://example.com" + link
+	}
+
+	first, err := c.get(link)
+	if err != nil {
+		return report, err
+	}
+
+	maxPages := c.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	pages := []page{first}
+	visited := map[string]bool{link: true}
+	complete := true
+	for current := first; current.Links.Next != "" && current.HasMore; {
+		next := current.Links.Next
+		// a next link to the page itself ends the crawl, it is reported as a boundary link of the last page
+		if next == current.url {
+			break
+		}
+		if visited[next] {
+			report.add(current, InvariantCrawl, "next link %s returns to a crawled page", next)
+			complete = false
+			break
+		}
+		if len(pages) >= maxPages {
+			report.add(current, InvariantCrawl, "stopped beyond %d pages", maxPages)
+			complete = false
+			break
+		}
+
+		visited[next] = true
+		fetched, err := c.get(next)
+		if err != nil {
+			return report, err
+		}
+		if crawled(pages, fetched.Page) {
+			report.add(current, InvariantCrawl, "next link %s returns to the crawled page %d", next, fetched.Page)
+			complete = false
+			break
+		}
+		current = fetched
+		pages = append(pages, current)
+	}
+
+	report.Pages = len(pages)
+	c.checkItems(report, pages, complete)
+	c.checkPages(report, pages, complete)
+	if err := c.checkLinks(report, pages, complete); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// crawled returns whether the numbered page is crawled
+func crawled(pages []page, number int64) bool {
+	for _, p := range pages {
+		if number > 0 && p.Page == number {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Report) add(p page, invariant, format string, args ...interface{}) {
+	r.Violations = append(r.Violations, Violation{invariant, p.Page, p.url, fmt.Sprintf(format, args...)})
+}
+
+// get fetches and reads the page of the link
+func (c Checker) get(link string) (page, error) {
+	var resp *http.Response
+	if c.Handler != nil {
+		w := httptest.NewRecorder()
+		c.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+		resp = w.Result()
+	} else {
+		httpClient := c.Client
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		var err error
+		if resp, err = httpClient.Get(link); err != nil {
+			return page{}, err
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return page{}, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return page{}, fmt.Errorf("%s responded %d: %s", link, resp.StatusCode, body)
+	}
+
+	base, err := url.Parse(link)
+	if err != nil {
+		return page{}, err
+	}
+	nav, err := client.Parse(resp.Header, body, base)
+	if err != nil {
+		return page{}, fmt.Errorf("%s: %v", link, err)
+	}
+	if nav.Items == nil {
+		return page{}, fmt.Errorf("%s: no items found in the response", link)
+	}
+
+	p := page{url: link, Navigation: nav}
+	for _, item := range nav.Items {
+		p.ids = append(p.ids, c.id(item))
+	}
+
+	return p, nil
+}
+
+func (c Checker) id(item json.RawMessage) string {
+	if c.ID != nil {
+		return c.ID(item)
+	}
+
+	var object struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(item, &object) == nil && len(object.ID) > 0 {
+		return string(object.ID)
+	}
+
+	return string(item)
+}
+
+// checkItems checks the items are neither duplicated nor missing, the items add up to the total only if the crawl is complete
+func (c Checker) checkItems(report *Report, pages []page, complete bool) {
+	seen := map[string]int64{}
+	for _, p := range pages {
+		for _, id := range p.ids {
+			if at, ok := seen[id]; ok {
+				report.add(p, InvariantDuplicate, "item %s was on page %d", id, at)
+				continue
+			}
+			seen[id] = p.Page
+		}
+	}
+	report.Items = len(seen)
+
+	last := pages[len(pages)-1]
+	for _, p := range pages {
+		if p.HasTotal && p.Total != pages[0].Total {
+			report.add(p, InvariantTotal, "total is %d, the first page reported %d", p.Total, pages[0].Total)
+		}
+	}
+	if !complete || !last.HasTotal || int64(report.Items) == last.Total {
+		return
+	}
+
+	detail := fmt.Sprintf("%d distinct items are crawled, want the total %d", report.Items, last.Total)
+	if gaps := diffGaps(pages, last.Total); int64(report.Items) < last.Total && gaps != "" {
+		detail += ", the gaps follow the pages short of items:\n" + gaps
+	}
+	report.add(last, InvariantTotal, "%s", detail)
+}
+
+// diffGaps returns the readable diff of the crawled pages and the gaps of the missing items.
+// A page holding fewer new items than the total leaves to it is followed by its gap prefixed by "-",
+// it is empty if no gap is found
+func diffGaps(pages []page, total int64) string {
+	var lines []string
+	found := false
+	seen := map[string]bool{}
+	offset := int64(0)
+
+	for _, p := range pages {
+		fresh := int64(0)
+		for _, id := range p.ids {
+			if !seen[id] {
+				seen[id] = true
+				fresh++
+			}
+		}
+
+		switch len(p.ids) {
+		case 0:
+			lines = append(lines, fmt.Sprintf("  page %d: no items", p.Page))
+		case 1:
+			lines = append(lines, fmt.Sprintf("  page %d: %s", p.Page, p.ids[0]))
+		default:
+			lines = append(lines, fmt.Sprintf("  page %d: %s ... %s", p.Page, p.ids[0], p.ids[len(p.ids)-1]))
+		}
+
+		if p.PageSize <= 0 {
+			continue
+		}
+		want := total - offset
+		if want > p.PageSize {
+			want = p.PageSize
+		}
+		offset += p.PageSize
+		if want > fresh {
+			lines = append(lines, fmt.Sprintf("- %d missing", want-fresh))
+			found = true
+		}
+	}
+
+	if !found {
+		return ""
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkPages checks the page sizes, the page numbers and the last page
+func (c Checker) checkPages(report *Report, pages []page, complete bool) {
+	first := pages[0]
+	for i, p := range pages {
+		if p.Page > 0 && first.Page > 0 && p.Page != first.Page+int64(i) {
+			report.add(p, InvariantPage, "page %d follows page %d", p.Page, pages[i-1].Page)
+		}
+
+		if p.PageSize <= 0 {
+			continue
+		}
+		n := int64(len(p.ids))
+		switch {
+		case i < len(pages)-1 && n != p.PageSize:
+			report.add(p, InvariantPageSize, "%d items, want a full page of %d", n, p.PageSize)
+		case i == len(pages)-1 && n > p.PageSize:
+			report.add(p, InvariantPageSize, "%d items, want at most %d", n, p.PageSize)
+		case i == len(pages)-1 && n == 0 && len(pages) > 1:
+			report.add(p, InvariantPageSize, "the last page is empty")
+		}
+	}
+
+	last := pages[len(pages)-1]
+	if !complete || !last.HasTotal || last.PageSize <= 0 {
+		return
+	}
+
+	want := (last.Total + last.PageSize - 1) / last.PageSize
+	if want == 0 {
+		want = 1
+	}
+	if int64(len(pages)) != want {
+		report.add(last, InvariantLast, "%d pages are crawled, want %d pages of %d items by the total %d", len(pages), want, last.PageSize, last.Total)
+	}
+	if first.Page > 0 && last.Last > 0 && last.Last != first.Page+want-1 {
+		report.add(last, InvariantLast, "last page is %d, want %d by the total %d", last.Last, first.Page+want-1, last.Total)
+	}
+}
+
+// checkLinks checks the prev links of the next pages and the boundary links
+func (c Checker) checkLinks(report *Report, pages []page, complete bool) error {
+	for i := 1; i < len(pages); i++ {
+		p, prev := pages[i], pages[i-1]
+		if p.Links.Prev == "" {
+			report.add(p, InvariantPrev, "no prev link, want the link of page %d", prev.Page)
+			continue
+		}
+
+		back, err := c.get(p.Links.Prev)
+		if err != nil {
+			return err
+		}
+		if diff := diffIDs(prev.ids, back.ids); diff != "" {
+			report.add(p, InvariantPrev, "prev link %s doesn't return to page %d:\n%s", p.Links.Prev, prev.Page, diff)
+		}
+	}
+
+	first, last := pages[0], pages[len(pages)-1]
+	if first.Links.Prev != "" {
+		report.add(first, InvariantBoundary, "the first page has a prev link %s", first.Links.Prev)
+	}
+	if complete && last.Links.Next != "" {
+		report.add(last, InvariantBoundary, "the last page has a next link %s", last.Links.Next)
+	}
+
+	if link := last.Links.First; link != "" {
+		target, err := c.get(link)
+		if err != nil {
+			return err
+		}
+		if diff := diffIDs(first.ids, target.ids); diff != "" {
+			report.add(last, InvariantBoundary, "first link %s doesn't return the first page:\n%s", link, diff)
+		}
+	}
+	if link := first.Links.Last; link != "" && complete {
+		target, err := c.get(link)
+		if err != nil {
+			return err
+		}
+		if diff := diffIDs(last.ids, target.ids); diff != "" {
+			report.add(first, InvariantBoundary, "last link %s doesn't return the last page:\n%s", link, diff)
+		}
+	}
+
+	return nil
+}
+
+// diffIDs returns the readable diff of the item ids, the wanted ones are prefixed by "-" and the got ones by "+".
+// It returns an empty string if they are the same.
+func diffIDs(want, got []string) string {
+	if strings.Join(want, "\x00") == strings.Join(got, "\x00") {
+		return ""
+	}
+
+	// the longest common subsequence keeps the shared items in place
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			switch {
+			case want[i] == got[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			lines = append(lines, "  "+want[i])
+			i++
+			j++
+		case j == len(got) || i < len(want) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+want[i])
+			i++
+		default:
+			lines = append(lines, "+ "+got[j])
+			j++
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,163 @@
+package paginationtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+type item struct {
+	ID int `json:"id"`
+}
+
+type items []item
+
+func (s items) Len() int                                    { return len(s) }
+func (s items) Slice(start, end int) pagination.Truncatable { return s[start:end] }
+
+// handler serves 12 items paginated by this library, bug breaks an invariant
+func handler(bug string) http.Handler {
+	all := make(items, 12)
+	for i := range all {
+		all[i] = item{i}
+	}
+	pg := pagination.DefaultPagination()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pgt := pg.Parse(r.URL.String())
+		total := int64(len(all))
+		start, end := pgt.GetRange()
+
+		switch nav := pgt.GetIndicator(); {
+		case bug == "skip" && nav.Page > 1:
+			start, end = start+1, end+1
+		case bug == "overlap" && nav.Page > 1:
+			start, end = start-1, end-1
+		case bug == "total":
+			total = 13
+		case bug == "short" && nav.Page == 2:
+			end--
+		}
+		if end > int64(len(all)) {
+			end = int64(len(all))
+		}
+
+		paginated := pgt.Wrap(all[start:end], total)
+		switch {
+		case bug == "prev" && paginated.Pagination.Page == 3:
+			paginated.Pagination.Prev = paginated.Pagination.First
+		case bug == "loop" && paginated.Pagination.Page == 2:
+			paginated.Pagination.Next = paginated.Pagination.First
+		case bug == "last":
+			paginated.Pagination.Last = strings.Replace(paginated.Pagination.Last, "page=3", "page=4", 1)
+		case bug == "boundary" && paginated.Pagination.Page == 1:
+			paginated.Pagination.Prev = strings.Replace(paginated.Pagination.Prev, "page=1", "page=0", 1)
+		}
+
+		json.NewEncoder(w).Encode(paginated)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		testName   string
+		bug        string
+		pages      int
+		items      int
+		invariants []string
+	}{
+		{"conformant", "", 3, 12, nil},
+		{"next skips items", "skip", 3, 11, []string{InvariantTotal}},
+		{"pages overlap", "overlap", 3, 11, []string{InvariantDuplicate, InvariantTotal}},
+		{"wrong total", "total", 4, 12, []string{InvariantTotal, InvariantPageSize}},
+		{"short page", "short", 3, 11, []string{InvariantTotal, InvariantPageSize}},
+		{"broken prev", "prev", 3, 12, []string{InvariantPrev}},
+		{"next loops", "loop", 2, 8, []string{InvariantCrawl}},
+		{"wrong last link", "last", 3, 12, []string{InvariantLast, InvariantBoundary, InvariantBoundary}},
+		{"prev of the first page", "boundary", 3, 12, []string{InvariantBoundary}},
+	}
+
+	for i, test := range tests {
+		report, err := Checker{Handler: handler(test.bug)}.Check("/items?page_size=4")
+		if err != nil {
+			t.Errorf("%d. [%s] check: %v", i, test.testName, err)
+			continue
+		}
+
+		var invariants []string
+		for _, v := range report.Violations {
+			invariants = append(invariants, v.Invariant)
+		}
+		if !reflect.DeepEqual(invariants, test.invariants) {
+			t.Errorf("%d. [%s] violations: got %v, want %v\n%s", i, test.testName, invariants, test.invariants, report)
+		}
+		if report.Pages != test.pages || report.Items != test.items {
+			t.Errorf("%d. [%s] crawled: got %d pages of %d items, want %d of %d", i, test.testName, report.Pages, report.Items, test.pages, test.items)
+		}
+		if report.OK() != (test.invariants == nil) {
+			t.Errorf("%d. [%s] ok: got %v", i, test.testName, report.OK())
+		}
+	}
+}
+
+func TestCheckServer(t *testing.T) {
+	server := httptest.NewServer(handler(""))
+	defer server.Close()
+
+	if report := Check(t, handler(""), "/items?page_size=5"); report.Pages != 3 {
+		t.Errorf("check handler: got %d pages, want 3", report.Pages)
+	}
+
+	report, err := Checker{Client: server.Client()}.Check(server.URL + "/items?page_size=5")
+	if err != nil || !report.OK() || report.Pages != 3 {
+		t.Errorf("check server: got %v, %v", report, err)
+	}
+
+	if _, err := (Checker{Handler: http.NotFoundHandler()}).Check("/items"); err == nil {
+		t.Errorf("check a missing endpoint: expects an error")
+	}
+}
+
+func TestReport(t *testing.T) {
+	report, _ := Checker{Handler: handler("prev")}.Check("/items?page_size=4")
+
+	want := strings.Join([]string{
+		"pagination: 1 violations in 3 pages of 12 items",
+		"    page 3 (http://example.com/items?page=3&page_size=4): prev of next: prev link http://example.com/items?page=1&page_size=4 doesn't return to page 2:",
+		"      - 4",
+		"      - 5",
+		"      - 6",
+		"      - 7",
+		"      + 0",
+		"      + 1",
+		"      + 2",
+		"      + 3",
+	}, "\n")
+	if got := report.String(); got != want {
+		t.Errorf("report:\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	report, _ = Checker{Handler: handler("short")}.Check("/items?page_size=4")
+	gaps := strings.Join([]string{
+		"total: 11 distinct items are crawled, want the total 12, the gaps follow the pages short of items:",
+		"        page 1: 0 ... 3",
+		"        page 2: 4 ... 6",
+		"      - 1 missing",
+		"        page 3: 8 ... 11",
+	}, "\n")
+	if got := report.String(); !strings.Contains(got, gaps) {
+		t.Errorf("report:\ngot\n%s\nwant it contains\n%s", got, gaps)
+	}
+
+	if got, want := diffIDs([]string{"1", "2", "3"}, []string{"2", "3", "4"}), "- 1\n  2\n  3\n+ 4"; got != want {
+		t.Errorf("diff:\ngot\n%s\nwant\n%s", got, want)
+	}
+	if got := diffIDs([]string{"1"}, []string{"1"}); got != "" {
+		t.Errorf("diff of the same ids: got %q", got)
+	}
+}
@@ -1,11 +1,17 @@
 package pagination
 
 import (
+	"errors"
 	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/zheeeng/pagination/fieldsets"
+	"github.com/zheeeng/pagination/filters"
 	"github.com/zheeeng/pagination/pager"
 	"github.com/zheeeng/pagination/queries"
+	"github.com/zheeeng/pagination/sorting"
+	"github.com/zheeeng/pagination/uritemplate"
 )
 
 // Truncatable is used for feeding Paginator::Wrap and Paginator::WrapWithTruncate, to wrap items into paginated result
@@ -14,80 +20,333 @@ type Truncatable interface {
 	Slice(startIndex, endIndex int) Truncatable
 }
 
+// RankFunc returns the zero-based index of the item identified by key in the whole ordered list,
+// e.g. the result of `SELECT COUNT(*) FROM books WHERE sort_key < ?`.
+// A negative rank means the item doesn't exist.
+type RankFunc func(key string) (rank int64, err error)
+
+// ErrItemNotFound is returned by Paginator::Locate when the rank lookup reports a missing item
+var ErrItemNotFound = errors.New("pagination: item not found")
+
+// ParseErrors collects the problems found in the parsed link, Paginator falls back to defaults for them
+type ParseErrors []error
+
+func (e ParseErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
 // Paginator provides methods to manipulate pagination fields
 type Paginator struct {
 	pager           *pager.Pager
 	basePath        string
 	queries         queries.PaginationQueries
-	defaultPageSize int
+	defaultPageSize int64
 	hasPage         bool
 	hasPageSize     bool
+	location        *Location
+	sortParam       string
+	sort            sorting.Orders
+	filterParam     string
+	filterKeys      []string
+	filter          filters.Expr
+	fieldsetParam   string
+	fieldset        fieldsets.Fields
+	rangeUnit       string
+	hasRange        bool
+	unalignedRange  bool
+	outOfRange      OutOfRangePolicy
+	preserveOrder   bool
+	params          queries.Params
+	namespace       string
+	nested          []nested
+	pathPattern     pathPattern
+	template        *uritemplate.Template
+	templateValues  uritemplate.Values
+	envelope        Envelope
+	errs            ParseErrors
+	paramErrs       BindErrors
+}
+
+// queryWriter is implemented by url.Values and *queries.OrderedQuery
+type queryWriter interface {
+	Set(key, value string)
+	Del(key string)
+	Encode() string
+}
+
+// setQueryFields writes the pagination related parameters to the query
+func (p *Paginator) setQueryFields(query queryWriter, page, pageSize int64) queryWriter {
+	query.Set(p.params.Page, strconv.FormatInt(page, 10))
+	query.Set(p.params.PageSize, strconv.FormatInt(pageSize, 10))
+
+	if p.sortParam != "" {
+		if len(p.sort) > 0 {
+			query.Set(p.sortParam, p.sort.String())
+		} else {
+			query.Del(p.sortParam)
+		}
+	}
+
+	if p.filterParam != "" {
+		for _, key := range p.filterKeys {
+			query.Del(key)
+		}
+		if p.filter != nil {
+			query.Set(p.filterParam, p.filter.String())
+		} else {
+			query.Del(p.filterParam)
+		}
+	}
+
+	if p.fieldsetParam != "" {
+		if len(p.fieldset) > 0 {
+			query.Set(p.fieldsetParam, p.fieldset.String())
+		} else {
+			query.Del(p.fieldsetParam)
+		}
+	}
+
+	return query
+}
+
+// paramError records the error of parsing the parameter, Bind reports it as the *BindError of the parameter
+func (p *Paginator) paramError(param string, err error) {
+	p.errs = append(p.errs, err)
+	p.paramErrs = append(p.paramErrs, &BindError{param, "is invalid: " + err.Error()})
+}
+
+func (p *Paginator) parseSort(cfg *SortConfiguration) {
+	p.sortParam = cfg.Param
+
+	orders, err := sorting.Parse(p.queries.Query.Get(cfg.Param), cfg.Fields)
+	if err != nil {
+		p.paramError(cfg.Param, err)
+	}
+
+	if len(orders) == 0 {
+		orders, _ = sorting.Parse(cfg.Default, cfg.Fields)
+	}
+
+	p.sort = orders
+}
+
+func (p *Paginator) parseFilter(cfg *FilterConfiguration) {
+	p.filterParam = cfg.Param
+
+	// the filter is read from the raw query, a ";" of the RSQL conjunction doesn't separate the query pairs
+	var raw string
+	if values := queries.RawValues(p.queries.RawQuery, cfg.Param); len(values) > 0 {
+		raw = values[0]
+	}
+
+	expr, err := filters.ParseRSQL(raw)
+	if err != nil {
+		p.paramError(cfg.Param, err)
+	}
+
+	if cfg.Brackets {
+		bracketExpr, keys, err := filters.ParseBrackets(p.namespacedQuery())
+		for _, key := range keys {
+			p.filterKeys = append(p.filterKeys, namespaced(p.namespace, key))
+		}
+		if e, ok := err.(*filters.SyntaxError); ok {
+			p.paramError(namespaced(p.namespace, e.Input), err)
+		} else if err != nil {
+			p.paramError(cfg.Param, err)
+		} else {
+			expr = filters.Join(expr, bracketExpr)
+		}
+	}
+
+	if expr != nil && cfg.Schema != nil {
+		if expr, err = cfg.Schema.Validate(expr); err != nil {
+			p.paramError(cfg.Param, err)
+			return
+		}
+	}
+
+	p.filter = expr
+}
+
+func (p *Paginator) parseFieldset(cfg *FieldsetConfiguration) {
+	p.fieldsetParam = cfg.Param
+
+	fields, err := fieldsets.Parse(p.queries.Query.Get(cfg.Param), cfg.Fields)
+	if err != nil {
+		p.paramError(cfg.Param, err)
+	}
+
+	if len(fields) == 0 {
+		fields, _ = fieldsets.Parse(cfg.Default, cfg.Fields)
+	}
+
+	p.fieldset = fields
+}
+
+// buildLink writes the pagination related parameters to the query and returns the link to the page,
+// the query is merged into the original ordered query if the query order is preserved.
+// The page and page size are moved to the path if a path pattern is configured,
+// and the link is expanded from the link template if it is configured.
+func (p *Paginator) buildLink(query url.Values, page, pageSize int64) string {
+	var writer queryWriter = query
+	if p.preserveOrder {
+		ordered := p.queries.Ordered.Clone()
+		ordered.Merge(query)
+		writer = &ordered
+	}
+	p.setQueryFields(writer, page, pageSize)
+
+	if p.template != nil {
+		return p.template.Expand(p.expansionValues(writer))
+	}
+
+	if p.pathPattern == nil {
+		return p.basePath + "?" + writer.Encode()
+	}
+
+	writer.Del(p.params.Page)
+	if p.pathPattern.has(pathPageSizeVariable) {
+		writer.Del(p.params.PageSize)
+	}
+
+	link := p.basePath + p.pathPattern.expand(page, pageSize)
+	if encoded := writer.Encode(); encoded != "" {
+		link += "?" + encoded
+	}
+
+	return link
+}
+
+// pageLink returns the link to an arbitrary page in the Paginator context
+func (p *Paginator) pageLink(page int64) string {
+	query := url.Values{}
+	for key, values := range p.queries.FirstQuery {
+		query[key] = append([]string(nil), values...)
+	}
+
+	return p.buildLink(query, page, p.pager.GetNavigation().PageSize)
 }
 
 func (p *Paginator) buildFields() *PageFields {
 	nav := p.pager.GetNavigation()
+	offset, _ := p.pager.GetOffsetRange()
 
 	fields := &PageFields{
 		Page:     nav.Page,
 		PageSize: nav.PageSize,
 		Total:    nav.Total,
 		Query:    p.queries.Query,
+		Location: p.location,
+		offset:   offset,
+		base:     nav.First,
+		pageLink: p.pageLink,
+		envelope: p.envelope,
+	}
+
+	if nav.Total > 0 {
+		fields.pages = nav.Last - nav.First + 1
 	}
 
-	p.queries.Query.Set("page", strconv.Itoa(nav.Page))
-	p.queries.Query.Set("page_size", strconv.Itoa(nav.PageSize))
+	p.setQueryFields(p.queries.Query, nav.Page, nav.PageSize)
 
-	p.queries.FirstQuery.Set("page", strconv.Itoa(nav.First))
-	p.queries.FirstQuery.Set("page_size", strconv.Itoa(nav.PageSize))
-	fields.First = p.basePath + "?" + p.queries.FirstQuery.Encode()
+	fields.First = p.buildLink(p.queries.FirstQuery, nav.First, nav.PageSize)
 
-	if nav.Last > 0 {
-		p.queries.LastQuery.Set("page", strconv.Itoa(nav.Last))
-		p.queries.LastQuery.Set("page_size", strconv.Itoa(nav.PageSize))
-		fields.Last = p.basePath + "?" + p.queries.LastQuery.Encode()
+	if nav.Total > 0 {
+		fields.Last = p.buildLink(p.queries.LastQuery, nav.Last, nav.PageSize)
 	}
 
-	p.queries.PrevQuery.Set("page", strconv.Itoa(nav.Prev))
-	p.queries.PrevQuery.Set("page_size", strconv.Itoa(nav.PageSize))
-	fields.Prev = p.basePath + "?" + p.queries.PrevQuery.Encode()
+	fields.Prev = p.buildLink(p.queries.PrevQuery, nav.Prev, nav.PageSize)
+	fields.Next = p.buildLink(p.queries.NextQuery, nav.Next, nav.PageSize)
 
-	p.queries.NextQuery.Set("page", strconv.Itoa(nav.Next))
-	p.queries.NextQuery.Set("page_size", strconv.Itoa(nav.PageSize))
-	fields.Next = p.basePath + "?" + p.queries.NextQuery.Encode()
+	if p.template != nil {
+		fields.Templated = p.templatedLink(p.queries.FirstQuery, nav.PageSize)
+	}
 
 	return fields
 }
 
 // Wrap is used for putting the input items to Result field of the Paginated struct.
-func (p *Paginator) Wrap(items Truncatable, total int) Paginated {
-	p.pager.SetTotal(total)
-	fields := p.buildFields()
+// The Result holds the items as they are, or their Objects if a fieldset is selected or sub-collections are nested.
+// The out-of-range policy is applied, the OutOfRangeRedirect and OutOfRangeNotFound policies serve an empty page,
+// the redirect link is kept in Pagination.Redirect, use TryWrap to get their errors.
+func (p *Paginator) Wrap(items Truncatable, total int64) Paginated {
+	paginated, _ := p.TryWrap(items, total)
+
+	return paginated
+}
+
+// TryWrap does the same thing with Wrap,
+// and it returns a *PageRedirectError or *PageNotFoundError by the out-of-range policy,
+// or the error of paginating the nested sub-collections
+func (p *Paginator) TryWrap(items Truncatable, total int64) (Paginated, error) {
+	fields, err := p.wrapFields(total)
+
+	result, resultErr := p.result(items)
+	if err == nil {
+		err = resultErr
+	}
 
 	return Paginated{
 		Pagination: fields,
-		Result:     items,
+		Result:     result,
+	}, err
+}
+
+// result nests the sub-collections of the items and projects them to the selected fieldset
+func (p *Paginator) result(items Truncatable) (Truncatable, error) {
+	result, err := p.nest(items)
+	if err != nil {
+		return result, err
 	}
+
+	return p.project(result)
 }
 
 // WrapWithTruncate does the same thing with Wrap,
 // and it truncates the input items by the pagination range.
 // It may cause a panic if items is not Slice kind
-func (p *Paginator) WrapWithTruncate(items Truncatable, total int) Paginated {
-	p.pager.SetTotal(total)
-	fields := p.buildFields()
+func (p *Paginator) WrapWithTruncate(items Truncatable, total int64) Paginated {
+	paginated, _ := p.TryWrapWithTruncate(items, total)
 
-	length := items.Len()
+	return paginated
+}
 
-	startIndex, endIndex := p.GetRange()
+// TryWrapWithTruncate does the same thing with WrapWithTruncate,
+// and it returns a *PageRedirectError or *PageNotFoundError by the out-of-range policy,
+// or the error of paginating the nested sub-collections
+func (p *Paginator) TryWrapWithTruncate(items Truncatable, total int64) (Paginated, error) {
+	fields, err := p.wrapFields(total)
 
-	if endIndex > length {
-		endIndex = length
+	result, resultErr := p.result(p.truncate(items))
+	if err == nil {
+		err = resultErr
 	}
 
 	return Paginated{
 		Pagination: fields,
-		Result:     items.Slice(startIndex, endIndex),
+		Result:     result,
+	}, err
+}
+
+// truncate slices the items by the pagination range
+func (p *Paginator) truncate(items Truncatable) Truncatable {
+	length := int64(items.Len())
+
+	startIndex, endIndex := p.GetRange()
+
+	if endIndex > length {
+		endIndex = length
+	}
+	if startIndex > endIndex {
+		startIndex = endIndex
 	}
+
+	return items.Slice(int(startIndex), int(endIndex))
 }
 
 // Query returns queries manipulation interface
@@ -95,33 +354,98 @@ func (p *Paginator) Query() url.Values {
 	return p.queries.Query
 }
 
-// SetPageInfo resets page and pageSize to pager
-func (p *Paginator) SetPageInfo(page, pageSize int) *Paginator {
+// PageParams returns the names of the page and page size parameters
+func (p *Paginator) PageParams() queries.Params {
+	return p.params
+}
+
+// SetPageInfo resets page and pageSize to pager, page is numbered from the configured page base
+func (p *Paginator) SetPageInfo(page, pageSize int64) *Paginator {
 	p.pager.SetPageInfo(page, pageSize)
 
 	return p
 }
 
 // GetRangeByIndex returns the corresponding start and end offsets by a specific item index number
-func (p *Paginator) GetRangeByIndex(index int) (start, end int) {
+func (p *Paginator) GetRangeByIndex(index int64) (start, end int64) {
 	return p.pager.ClonePagerWithCursor(index, p.pager.GetNavigation().PageSize).GetRange()
 }
 
+// Locate moves the Paginator to the page containing the item identified by key,
+// and returns the item position within that page. A failed Locate clears the previous location.
+func (p *Paginator) Locate(key string, rank RankFunc) (position int64, err error) {
+	p.location = nil
+
+	index, err := rank(key)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		return 0, ErrItemNotFound
+	}
+
+	page, position := p.pager.LocateIndex(index)
+	p.pager.SetPageInfo(page, p.pager.GetNavigation().PageSize)
+	p.location = &Location{
+		Key:      key,
+		Index:    index,
+		Position: position,
+	}
+
+	return position, nil
+}
+
+// GetLocation returns the item location resolved by Locate, it is nil if Locate hasn't succeeded
+func (p *Paginator) GetLocation() *Location {
+	return p.location
+}
+
 // GetRange returns the corresponding start and end offsets by Paginator context
-func (p *Paginator) GetRange() (start, end int) {
+func (p *Paginator) GetRange() (start, end int64) {
 	return p.pager.GetRange()
 }
 
 // GetOffsetRangeByIndex returns the corresponding offset and range length by a specific item index number
-func (p *Paginator) GetOffsetRangeByIndex(index int) (offset, length int) {
+func (p *Paginator) GetOffsetRangeByIndex(index int64) (offset, length int64) {
 	return p.pager.ClonePagerWithCursor(index, p.pager.GetNavigation().PageSize).GetOffsetRange()
 }
 
-// GetOffsetRange returns the corresponding offset and range length by Paginator context
-func (p *Paginator) GetOffsetRange() (offset, length int) {
+// GetOffsetRange returns the corresponding offset and range length by Paginator context,
+// the offset is clamped to math.MaxInt64 if it overflows
+func (p *Paginator) GetOffsetRange() (offset, length int64) {
 	return p.pager.GetOffsetRange()
 }
 
+// GetCheckedOffsetRange does the same thing with GetOffsetRange, and it returns pager.ErrOverflow if the offset overflows
+func (p *Paginator) GetCheckedOffsetRange() (offset, length int64, err error) {
+	return p.pager.GetCheckedOffsetRange()
+}
+
+// Sort returns the normalized sort orders, it is empty if sorting isn't configured
+func (p *Paginator) Sort() sorting.Orders {
+	return p.sort
+}
+
+// Fieldset returns the selected fields of the items, it is empty if the fieldset isn't configured or selected
+func (p *Paginator) Fieldset() fieldsets.Fields {
+	return p.fieldset
+}
+
+// Filter returns the parsed filter expression, it is nil if filtering isn't configured or the link has no filter.
+// Invalid filters are dropped and reported by Err.
+func (p *Paginator) Filter() filters.Expr {
+	return p.filter
+}
+
+// Err returns the problems found when parsing the link, it is nil if there is none
+func (p *Paginator) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+
+	return p.errs
+}
+
 // GetIndicator returns current page, pageSize, total and tother info in its context
 func (p *Paginator) GetIndicator() pager.Navigation {
 	return p.pager.GetNavigation()
@@ -0,0 +1,226 @@
+package pagination_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+	"github.com/zheeeng/pagination/filters"
+)
+
+func TestLocate(t *testing.T) {
+	rank := func(key string) (int64, error) {
+		switch key {
+		case "book-12":
+			return 12, nil
+		case "broken":
+			return 0, errors.New("db is down")
+		}
+		return -1, nil
+	}
+
+	pgt := pagination.DefaultPagination().Parse(requestURI)
+
+	position, err := pgt.Locate("book-12", rank)
+	if err != nil {
+		t.Fatalf("[Locate] unexpected error: %v", err)
+	}
+	if position != 2 {
+		t.Errorf("[Locate position]: got %d, want %d", position, 2)
+	}
+
+	paginated := pgt.WrapWithTruncate(TrunctableBooks(books), total)
+	fields := paginated.Pagination
+
+	if fields.Page != 3 {
+		t.Errorf("[Locate page]: got %d, want %d", fields.Page, 3)
+	}
+	if fields.Next != "api.example.com/books?author=jk&page=4&page_size=5" {
+		t.Errorf("[Locate next link]: got %s", fields.Next)
+	}
+	if fields.Location == nil || fields.Location.Key != "book-12" || fields.Location.Position != 2 {
+		t.Errorf("[Locate location]: got %+v", fields.Location)
+	}
+	if item := paginated.Result.(TrunctableBooks)[position]; item.ID != 12 {
+		t.Errorf("[Locate item]: got %d, want %d", item.ID, 12)
+	}
+
+	if _, err := pgt.Locate("missing", rank); err != pagination.ErrItemNotFound {
+		t.Errorf("[Locate missing]: got %v, want %v", err, pagination.ErrItemNotFound)
+	}
+	if location := pgt.GetLocation(); location != nil {
+		t.Errorf("[Locate missing location]: got %+v, want nil", location)
+	}
+	if fields := pgt.WrapWithTruncate(TrunctableBooks(books), total).Pagination; fields.Location != nil {
+		t.Errorf("[Locate missing fields location]: got %+v, want nil", fields.Location)
+	}
+	if _, err := pgt.Locate("broken", rank); err == nil || err.Error() != "db is down" {
+		t.Errorf("[Locate broken]: got %v", err)
+	}
+}
+
+func TestSort(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		PageSize: 5,
+		Sort: &pagination.SortConfiguration{
+			Fields:  []string{"created_at", "name"},
+			Default: "-created_at",
+		},
+	})
+
+	tests := []struct {
+		testName string
+		link     string
+		sort     string
+		next     string
+		hasErr   bool
+	}{
+		{"normalized sort", "api.example.com/books?sort=%2Bname,-created_at&page=2",
+			"name,-created_at", "api.example.com/books?page=3&page_size=5&sort=name%2C-created_at", false,
+		},
+		{"default sort", "api.example.com/books?author=jk",
+			"-created_at", "api.example.com/books?author=jk&page=2&page_size=5&sort=-created_at", false,
+		},
+		{"unsortable field", "api.example.com/books?sort=password",
+			"-created_at", "api.example.com/books?page=2&page_size=5&sort=-created_at", true,
+		},
+	}
+
+	for i, test := range tests {
+		pgt := pg.Parse(test.link)
+		fields := pgt.Wrap(TrunctableBooks(books[:5]), total).Pagination
+
+		if pgt.Sort().String() != test.sort {
+			t.Errorf("%d. [%s] sort: got %s, want %s", i, test.testName, pgt.Sort().String(), test.sort)
+		}
+		if fields.Next != test.next {
+			t.Errorf("%d. [%s] next link: got %s, want %s", i, test.testName, fields.Next, test.next)
+		}
+		if fields.Query.Get("sort") != test.sort {
+			t.Errorf("%d. [%s] query sort: got %s, want %s", i, test.testName, fields.Query.Get("sort"), test.sort)
+		}
+		if (pgt.Err() != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, pgt.Err(), test.hasErr)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		PageSize: 5,
+		Filter: &pagination.FilterConfiguration{
+			Brackets: true,
+			Schema: filters.Schema{
+				"author": {Type: filters.String},
+				"year":   {Type: filters.Int},
+				"price":  {Type: filters.Float},
+			},
+		},
+	})
+
+	tests := []struct {
+		testName string
+		link     string
+		filter   string
+		next     string
+		hasErr   bool
+	}{
+		{"rsql filter", "api.example.com/books?filter=author==jk%3Byear>2000",
+			"author==jk;year=gt=2000", "api.example.com/books?filter=author%3D%3Djk%3Byear%3Dgt%3D2000&page=2&page_size=5", false,
+		},
+		{"rsql filter with a literal semicolon", "api.example.com/books?filter=author==jk;year>2000",
+			"author==jk;year=gt=2000", "api.example.com/books?filter=author%3D%3Djk%3Byear%3Dgt%3D2000&page=2&page_size=5", false,
+		},
+		{"brackets filter", "api.example.com/books?price[gte]=10&q=x",
+			"price=ge=10", "api.example.com/books?filter=price%3Dge%3D10&page=2&page_size=5&q=x", false,
+		},
+		{"no filter", "api.example.com/books?q=x",
+			"", "api.example.com/books?page=2&page_size=5&q=x", false,
+		},
+		{"invalid filter", "api.example.com/books?filter=isbn==1",
+			"", "api.example.com/books?page=2&page_size=5", true,
+		},
+		{"invalid rsql filter with brackets filter", "api.example.com/books?filter=author==&price[gte]=10",
+			"price=ge=10", "api.example.com/books?filter=price%3Dge%3D10&page=2&page_size=5", true,
+		},
+	}
+
+	for i, test := range tests {
+		pgt := pg.Parse(test.link)
+		fields := pgt.Wrap(TrunctableBooks(books[:5]), total).Pagination
+
+		filter := ""
+		if pgt.Filter() != nil {
+			filter = pgt.Filter().String()
+		}
+		if filter != test.filter {
+			t.Errorf("%d. [%s] filter: got %s, want %s", i, test.testName, filter, test.filter)
+		}
+		if fields.Next != test.next {
+			t.Errorf("%d. [%s] next link: got %s, want %s", i, test.testName, fields.Next, test.next)
+		}
+		if (pgt.Err() != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, pgt.Err(), test.hasErr)
+		}
+	}
+
+	errs, _ := pg.Parse("api.example.com/books?filter=author==&price[between]=10").Err().(pagination.ParseErrors)
+	if len(errs) != 2 {
+		t.Errorf("rsql and brackets errors: got %v, want both", errs)
+	}
+}
+
+func TestZeroBasedPage(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		PageSize:      5,
+		ZeroBasedPage: true,
+	})
+
+	tests := []struct {
+		testName                string
+		link                    string
+		page                    int64
+		start, end              int64
+		first, last, prev, next string
+	}{
+		{"default page", "api.example.com/books", 0, 0, 5,
+			"api.example.com/books?page=0&page_size=5",
+			"api.example.com/books?page=3&page_size=5",
+			"api.example.com/books?page=0&page_size=5",
+			"api.example.com/books?page=1&page_size=5",
+		},
+		{"middle page", "api.example.com/books?page=1", 1, 5, 10,
+			"api.example.com/books?page=0&page_size=5",
+			"api.example.com/books?page=3&page_size=5",
+			"api.example.com/books?page=0&page_size=5",
+			"api.example.com/books?page=2&page_size=5",
+		},
+		{"last page", "api.example.com/books?page=3", 3, 15, 20,
+			"api.example.com/books?page=0&page_size=5",
+			"api.example.com/books?page=3&page_size=5",
+			"api.example.com/books?page=2&page_size=5",
+			"api.example.com/books?page=3&page_size=5",
+		},
+	}
+
+	for i, test := range tests {
+		pgt := pg.Parse(test.link)
+		start, end := pgt.GetRange()
+		paginated := pgt.Wrap(TrunctableBooks(books[start:end]), total)
+		fields := paginated.Pagination
+
+		if fields.Page != test.page || start != test.start || end != test.end {
+			t.Errorf("%d. [%s] page: got %d (%d, %d), want %d (%d, %d)",
+				i, test.testName, fields.Page, start, end, test.page, test.start, test.end)
+		}
+		if fields.First != test.first || fields.Last != test.last || fields.Prev != test.prev || fields.Next != test.next {
+			t.Errorf("%d. [%s] links: got %s %s %s %s", i, test.testName, fields.First, fields.Last, fields.Prev, fields.Next)
+		}
+		if spring := paginated.Spring(); spring.Number != test.page || spring.TotalPages != 4 {
+			t.Errorf("%d. [%s] spring number: got %d, want %d", i, test.testName, spring.Number, test.page)
+		}
+		if laravel := paginated.Laravel(); laravel.CurrentPage != test.page+1 || *laravel.Links[1].URL != test.first {
+			t.Errorf("%d. [%s] laravel: got %d %s", i, test.testName, laravel.CurrentPage, *laravel.Links[1].URL)
+		}
+	}
+}
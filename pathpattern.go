@@ -0,0 +1,117 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	pathPageVariable     = "{page}"
+	pathPageSizeVariable = "{page_size}"
+)
+
+// pathPattern is the parsed PathPattern, its segments are literals or the page and page size variables
+type pathPattern []string
+
+func parsePathPattern(pattern string) (pathPattern, error) {
+	segments := splitSegments(pattern)
+
+	seen := map[string]bool{}
+	for _, segment := range segments {
+		if !strings.ContainsAny(segment, "{}") {
+			continue
+		}
+		if segment != pathPageVariable && segment != pathPageSizeVariable {
+			return nil, fmt.Errorf("pagination: path pattern %q has an unknown variable %q", pattern, segment)
+		}
+		if seen[segment] {
+			return nil, fmt.Errorf("pagination: path pattern %q repeats the variable %q", pattern, segment)
+		}
+		seen[segment] = true
+	}
+	if !seen[pathPageVariable] {
+		return nil, fmt.Errorf("pagination: path pattern %q doesn't have the %s variable", pattern, pathPageVariable)
+	}
+
+	return pathPattern(segments), nil
+}
+
+func (pp pathPattern) has(variable string) bool {
+	for _, segment := range pp {
+		if segment == variable {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match matches the pattern against the tail of the base path,
+// it returns the base path without the tail and the page and page size segments
+func (pp pathPattern) match(basePath string) (trimmed, page, pageSize string, ok bool) {
+	origin, path := splitBasePath(basePath)
+	segments := splitSegments(path)
+	if len(segments) < len(pp) {
+		return basePath, "", "", false
+	}
+
+	tail := segments[len(segments)-len(pp):]
+	for i, segment := range pp {
+		switch segment {
+		case pathPageVariable:
+			page = tail[i]
+		case pathPageSizeVariable:
+			pageSize = tail[i]
+		default:
+			if segment != tail[i] {
+				return basePath, "", "", false
+			}
+		}
+	}
+
+	trimmed = strings.Join(segments[:len(segments)-len(pp)], "/")
+	if strings.HasPrefix(path, "/") || origin != "" {
+		trimmed = "/" + trimmed
+	}
+	// the root path is dropped, so the expanded tail doesn't make a protocol-relative link like "//page/4"
+	if trimmed == "/" {
+		trimmed = ""
+	}
+
+	return origin + trimmed, page, pageSize, true
+}
+
+// apply reads the page and page size from the base path, they override the ones read from the query
+func (pp pathPattern) apply(basePath string, page, pageSize int64, hasPage, hasPageSize bool) (string, int64, int64, bool, bool) {
+	trimmed, rawPage, rawPageSize, ok := pp.match(basePath)
+	if !ok {
+		return basePath, page, pageSize, hasPage, hasPageSize
+	}
+
+	if value, err := strconv.ParseInt(rawPage, 10, 64); err == nil {
+		page, hasPage = value, true
+	}
+	if value, err := strconv.ParseInt(rawPageSize, 10, 64); err == nil {
+		pageSize, hasPageSize = value, true
+	}
+
+	return trimmed, page, pageSize, hasPage, hasPageSize
+}
+
+// expand returns the path tail of the page
+func (pp pathPattern) expand(page, pageSize int64) string {
+	segments := make([]string, len(pp))
+	for i, segment := range pp {
+		switch segment {
+		case pathPageVariable:
+			segments[i] = strconv.FormatInt(page, 10)
+		case pathPageSizeVariable:
+			segments[i] = strconv.FormatInt(pageSize, 10)
+		default:
+			segments[i] = segment
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,118 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func TestPathPattern(t *testing.T) {
+	tests := []struct {
+		testName string
+		pattern  string
+		link     string
+		page     int64
+		pageSize int64
+		first    string
+		next     string
+		hasErr   bool
+	}{
+		{
+			"page segment",
+			"/page/{page}",
+			"api.example.com/books/page/3?author=jk&page_size=5",
+			3, 5,
+			"api.example.com/books/page/1?author=jk&page_size=5",
+			"api.example.com/books/page/4?author=jk&page_size=5",
+			false,
+		},
+		{
+			"page and size segments",
+			"/p/{page}/size/{page_size}",
+			"https://api.example.com/books/p/2/size/5",
+			2, 5,
+			"https://api.example.com/books/p/1/size/5",
+			"https://api.example.com/books/p/3/size/5",
+			false,
+		},
+		{
+			"root path",
+			"/page/{page}",
+			"api.example.com/page/2?page_size=5",
+			2, 5,
+			"api.example.com/page/1?page_size=5",
+			"api.example.com/page/3?page_size=5",
+			false,
+		},
+		{
+			"root path without origin",
+			"/page/{page}",
+			"/page/3?page_size=5",
+			3, 5,
+			"/page/1?page_size=5",
+			"/page/4?page_size=5",
+			false,
+		},
+		{
+			"no page segment",
+			"/p/{page}/size/{page_size}",
+			"/books?author=jk&page=2&page_size=5",
+			2, 5,
+			"/books/p/1/size/5?author=jk",
+			"/books/p/3/size/5?author=jk",
+			false,
+		},
+		{
+			"invalid page segment",
+			"/page/{page}",
+			"/books/page/two?page_size=5",
+			1, 5,
+			"/books/page/1?page_size=5",
+			"/books/page/2?page_size=5",
+			false,
+		},
+		{
+			"invalid pattern",
+			"/page/{number}",
+			"/books/page/2?page=3&page_size=5",
+			3, 5,
+			"/books/page/2?page=1&page_size=5",
+			"/books/page/2?page=4&page_size=5",
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		pg := pagination.NewPagination(pagination.PaginatorConfiguration{PathPattern: test.pattern})
+		pgt := pg.Parse(test.link)
+
+		if err := pgt.Err(); (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+		}
+
+		paginated := pgt.Wrap(TrunctableBooks(books), total)
+		if paginated.Pagination.Page != test.page || paginated.Pagination.PageSize != test.pageSize {
+			t.Errorf("%d. [%s] page: got %d/%d, want %d/%d", i, test.testName,
+				paginated.Pagination.Page, paginated.Pagination.PageSize, test.page, test.pageSize)
+		}
+		if paginated.Pagination.First != test.first {
+			t.Errorf("%d. [%s] first: got %s, want %s", i, test.testName, paginated.Pagination.First, test.first)
+		}
+		if paginated.Pagination.Next != test.next {
+			t.Errorf("%d. [%s] next: got %s, want %s", i, test.testName, paginated.Pagination.Next, test.next)
+		}
+	}
+}
+
+func TestPathPatternOrderedQuery(t *testing.T) {
+	pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+		PathPattern:        "/page/{page}",
+		PreserveQueryOrder: true,
+		ZeroBasedPage:      true,
+	})
+
+	paginated := pg.Parse("/books/page/0?z=1&page_size=5&a=2").Wrap(TrunctableBooks(books), total)
+	if want := "/books/page/1?z=1&page_size=5&a=2"; paginated.Pagination.Next != want {
+		t.Errorf("next: got %s, want %s", paginated.Pagination.Next, want)
+	}
+}
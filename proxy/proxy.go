@@ -0,0 +1,256 @@
+// Package proxy paginates the upstream endpoints responding whole JSON arrays.
+//
+// The array responded by the upstream is truncated to the page requested by the client,
+// and it is responded in the envelope of the Pagination with the Link and X-Total-Count headers.
+// A Range header, e.g. `Range: items=0-24`, is served with Content-Range headers,
+// an invalid or unaligned one is answered by 416 Range Not Satisfiable.
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zheeeng/pagination"
+)
+
+// items is the Truncatable of the upstream array
+type items []json.RawMessage
+
+func (s items) Len() int {
+	return len(s)
+}
+
+func (s items) Slice(startIndex, endIndex int) pagination.Truncatable {
+	return s[startIndex:endIndex]
+}
+
+// Handler paginates the JSON arrays responded by the upstream. By default:
+//
+// -- Pagination: pagination.DefaultPagination()
+//
+// -- Client: http.DefaultClient
+//
+// -- CacheTTL: 0, how long an upstream array is reused for the page requests of the same resource, 0 disables the cache
+//
+// The GET requests are forwarded without the page and page size parameters, the other methods are proxied untouched.
+// The upstream responses which aren't 2xx JSON arrays are relayed as they are.
+// A HEAD request is served as a GET without the body, its headers need the total,
+// so it costs the same as a GET: the upstream array is fetched and decoded unless it is cached.
+type Handler struct {
+	Upstream   *url.URL
+	Pagination pagination.Pagination
+	Client     *http.Client
+	CacheTTL   time.Duration
+
+	once    sync.Once
+	reverse *httputil.ReverseProxy
+	mu      sync.Mutex
+	cache   map[string]cached
+}
+
+type cached struct {
+	items   items
+	expires time.Time
+}
+
+// New returns a Handler of the upstream base URL, e.g. "http://legacy.internal:8080/api"
+func New(upstream string, pg pagination.Pagination) (*Handler, error) {
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{Upstream: u, Pagination: pg}, nil
+}
+
+// hopHeaders are the hop-by-hop headers which aren't forwarded
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade", "Range",
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.once.Do(func() {
+		h.reverse = httputil.NewSingleHostReverseProxy(h.Upstream)
+		h.cache = map[string]cached{}
+	})
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.reverse.ServeHTTP(w, r)
+		return
+	}
+
+	pg := h.Pagination
+	if pg == nil {
+		pg = pagination.DefaultPagination()
+	}
+	pgt := pg.Parse(r.URL.String())
+	rangeErr := pgt.ParseRange(r.Header.Get("Range"))
+
+	// the pagination parameters are served by the proxy, so the pages of a resource share the upstream response
+	query := r.URL.Query()
+	params := pgt.PageParams()
+	query.Del(params.Page)
+	query.Del(params.PageSize)
+
+	upstream := *h.Upstream
+	upstream.Path = singleJoiningSlash(h.Upstream.Path, r.URL.Path)
+	upstream.RawPath = ""
+	upstream.RawQuery = query.Encode()
+
+	all, ok := h.fetch(w, r, upstream.String())
+	if !ok {
+		return
+	}
+	total := int64(len(all))
+
+	// a Range header which can't be served as a page is answered by 416
+	if rangeErr != nil {
+		if contentRange, status := pgt.ContentRange(total); status == http.StatusRequestedRangeNotSatisfiable {
+			w.Header().Set("Content-Range", contentRange)
+		}
+		http.Error(w, rangeErr.Error(), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
+	paginated, err := pgt.TryWrapWithTruncate(all, total)
+	switch err := err.(type) {
+	case nil:
+	case *pagination.PageRedirectError:
+		http.Redirect(w, r, err.Location, http.StatusFound)
+		return
+	case *pagination.PageNotFoundError:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := json.Marshal(paginated)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	paginated.Pagination.WriteHeaders(w.Header())
+
+	if pgt.HasRawRange() {
+		if pgt.WriteRangeHeaders(w, total) == http.StatusRequestedRangeNotSatisfiable {
+			return
+		}
+	}
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash && b != "":
+		return a + "/" + b
+	}
+
+	return a + b
+}
+
+// cacheKey distinguishes the upstream responses by the link and the credentials
+func cacheKey(link string, r *http.Request) string {
+	return link + "\n" + r.Header.Get("Authorization") + "\n" + r.Header.Get("Cookie")
+}
+
+// fetch returns the upstream array, ok is false if the upstream response is relayed or an error is responded
+func (h *Handler) fetch(w http.ResponseWriter, r *http.Request, link string) (all items, ok bool) {
+	key := cacheKey(link, r)
+	if h.CacheTTL > 0 {
+		h.mu.Lock()
+		entry, hit := h.cache[key]
+		h.mu.Unlock()
+		if hit && time.Now().Before(entry.expires) {
+			return entry.items, true
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return nil, false
+	}
+	req = req.WithContext(r.Context())
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+	for _, name := range hopHeaders {
+		req.Header.Del(name)
+	}
+	// the client decompresses the upstream response only if it asks for the compression itself
+	req.Header.Del("Accept-Encoding")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return nil, false
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || len(trimmed) == 0 || trimmed[0] != '[' ||
+		json.Unmarshal(trimmed, &all) != nil {
+		relay(w, resp, body)
+		return nil, false
+	}
+	if all == nil {
+		all = items{}
+	}
+
+	if h.CacheTTL > 0 {
+		now := time.Now()
+		h.mu.Lock()
+		for k, entry := range h.cache {
+			if !now.Before(entry.expires) {
+				delete(h.cache, k)
+			}
+		}
+		h.cache[key] = cached{all, now.Add(h.CacheTTL)}
+		h.mu.Unlock()
+	}
+
+	return all, true
+}
+
+// relay responds the upstream response as it is
+func relay(w http.ResponseWriter, resp *http.Response, body []byte) {
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	for _, name := range hopHeaders {
+		w.Header().Del(name)
+	}
+	w.Header().Del("Content-Length")
+
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
@@ -0,0 +1,216 @@
+package proxy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zheeeng/pagination"
+	"github.com/zheeeng/pagination/paginationtest"
+)
+
+// newUpstream serves 12 books at /api/books and 2 tags at /api/tags, and counts the requests by their URIs
+func newUpstream() (*httptest.Server, map[string]int, *sync.Mutex) {
+	hits := map[string]int{}
+	var mu sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.Method+" "+r.URL.RequestURI()]++
+		mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":12}`))
+		case r.URL.Path == "/api/books":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":0},{"id":1},{"id":2},{"id":3},{"id":4},{"id":5},{"id":6},{"id":7},{"id":8},{"id":9},{"id":10},{"id":11}]`))
+		case r.URL.Path == "/api/tags":
+			w.Write([]byte(`["go","json"]`))
+		case r.URL.Path == "/api/empty":
+			w.Write([]byte(`[]`))
+		case r.URL.Path == "/api/status":
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			http.Error(w, "broken", http.StatusInternalServerError)
+		}
+	}))
+
+	return server, hits, &mu
+}
+
+func TestHandler(t *testing.T) {
+	upstream, hits, _ := newUpstream()
+	defer upstream.Close()
+
+	h, err := New(upstream.URL+"/api", pagination.NewPagination(pagination.PaginatorConfiguration{
+		PageSize:   5,
+		OutOfRange: pagination.OutOfRangeNotFound,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		testName string
+		method   string
+		target   string
+		rangeHdr string
+		status   int
+		body     string
+		link     string
+		total    string
+	}{
+		{
+			"middle page", "GET", "/books?author=jk&page=2", "", http.StatusOK,
+			`{"pagination":{"page":2,"page_size":5,"total":12,"first":"/books?author=jk\u0026page=1\u0026page_size=5","last":"/books?author=jk\u0026page=3\u0026page_size=5","prev":"/books?author=jk\u0026page=1\u0026page_size=5","next":"/books?author=jk\u0026page=3\u0026page_size=5","query":{"author":["jk"],"page":["2"],"page_size":["5"]}},"result":[{"id":5},{"id":6},{"id":7},{"id":8},{"id":9}]}`,
+			`</books?author=jk&page=1&page_size=5>; rel="first", </books?author=jk&page=1&page_size=5>; rel="prev", </books?author=jk&page=3&page_size=5>; rel="next", </books?author=jk&page=3&page_size=5>; rel="last"`,
+			"12",
+		},
+		{
+			"last page", "GET", "/books?author=jk&page=3&page_size=5", "", http.StatusOK,
+			`"result":[{"id":10},{"id":11}]}`,
+			`</books?author=jk&page=1&page_size=5>; rel="first", </books?author=jk&page=2&page_size=5>; rel="prev", </books?author=jk&page=3&page_size=5>; rel="last"`,
+			"12",
+		},
+		{
+			"range", "GET", "/books", "items=4-7", http.StatusPartialContent,
+			`"result":[{"id":4},{"id":5},{"id":6},{"id":7}]}`,
+			`</books?page=1&page_size=4>; rel="first", </books?page=1&page_size=4>; rel="prev", </books?page=3&page_size=4>; rel="next", </books?page=3&page_size=4>; rel="last"`,
+			"12",
+		},
+		{"invalid range", "GET", "/books", "items=-5", http.StatusRequestedRangeNotSatisfiable, "invalid range", "", ""},
+		{"unaligned range", "GET", "/books", "items=3-7", http.StatusRequestedRangeNotSatisfiable, "doesn't start at a page", "", ""},
+		{"out of range", "GET", "/books?page=9", "", http.StatusNotFound, "beyond the last page", "", ""},
+		{"empty", "GET", "/empty", "", http.StatusOK, `"result":[]}`, `</empty?page=1&page_size=5>; rel="first", </empty?page=2&page_size=5>; rel="next"`, "0"},
+		{"not an array", "GET", "/status", "", http.StatusOK, `{"ok":true}`, "", ""},
+		{"upstream error", "GET", "/missing", "", http.StatusInternalServerError, "broken", "", ""},
+		{"other methods", "POST", "/books?page=2", "", http.StatusCreated, `{"id":12}`, "", ""},
+	}
+
+	for i, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, strings.NewReader(""))
+		if test.rangeHdr != "" {
+			req.Header.Set("Range", test.rangeHdr)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		body, _ := ioutil.ReadAll(w.Body)
+		if w.Code != test.status {
+			t.Errorf("%d. [%s] status: got %d, want %d", i, test.testName, w.Code, test.status)
+		}
+		if !strings.Contains(string(body), test.body) {
+			t.Errorf("%d. [%s] body:\ngot  %s\nwant it contains %s", i, test.testName, body, test.body)
+		}
+		if got := w.Header().Get("Link"); got != test.link {
+			t.Errorf("%d. [%s] Link:\ngot  %s\nwant %s", i, test.testName, got, test.link)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != test.total {
+			t.Errorf("%d. [%s] X-Total-Count: got %q, want %q", i, test.testName, got, test.total)
+		}
+	}
+
+	if n := hits["GET /api/books?author=jk"]; n != 2 {
+		t.Errorf("upstream requests without pagination parameters: got %d, want 2 (%v)", n, hits)
+	}
+	if n := hits["POST /api/books?page=2"]; n != 1 {
+		t.Errorf("proxied requests: got %d, want 1 (%v)", n, hits)
+	}
+}
+
+func TestHandlerCache(t *testing.T) {
+	upstream, hits, mu := newUpstream()
+	defer upstream.Close()
+
+	h, err := New(upstream.URL+"/api/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.CacheTTL = 50 * time.Millisecond
+
+	get := func(target, authorization string) {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: got status %d", target, w.Code)
+		}
+	}
+
+	get("/books?page=1&page_size=5", "a")
+	get("/books?page=2&page_size=5", "a")
+	get("/books?page=2&page_size=5", "b")
+
+	mu.Lock()
+	if n := hits["GET /api/books"]; n != 2 {
+		t.Errorf("cached upstream requests: got %d, want 2 for two credentials", n)
+	}
+	mu.Unlock()
+
+	time.Sleep(60 * time.Millisecond)
+	get("/books?page=3&page_size=5", "a")
+
+	mu.Lock()
+	if n := hits["GET /api/books"]; n != 3 {
+		t.Errorf("expired upstream requests: got %d, want 3", n)
+	}
+	mu.Unlock()
+}
+
+// nesting nests a sub-collection into each item, it fails on the items which aren't JSON objects
+type nesting struct {
+	pagination.Pagination
+}
+
+func (pg nesting) Parse(link string) *pagination.Paginator {
+	return pg.Pagination.Parse(link).Nest(pagination.NestedConfiguration{
+		Field:    "editions",
+		Template: "/editions",
+		Load: func(item interface{}, child *pagination.Paginator) (pagination.Truncatable, int64, error) {
+			return items{}, 0, nil
+		},
+	})
+}
+
+func TestHandlerWrapError(t *testing.T) {
+	upstream, _, _ := newUpstream()
+	defer upstream.Close()
+
+	h, err := New(upstream.URL+"/api", nesting{pagination.DefaultPagination()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, method := range []string{"GET", "HEAD"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "/tags", nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%d. [%s] status: got %d, want %d", i, method, w.Code, http.StatusBadRequest)
+		}
+		if got := w.Header().Get("Link"); got != "" {
+			t.Errorf("%d. [%s] Link: got %s, want none", i, method, got)
+		}
+	}
+}
+
+func TestHandlerConformance(t *testing.T) {
+	upstream, _, _ := newUpstream()
+	defer upstream.Close()
+
+	h, err := New(upstream.URL+"/api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, link := range []string{"/books?page_size=5", "/books?page_size=4", "/books?page_size=20", "/empty"} {
+		paginationtest.Check(t, h, link)
+	}
+}
@@ -0,0 +1,211 @@
+package queries
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryPair defines a key-value pair of the query
+type QueryPair struct {
+	Key   string
+	Value string
+	// raw keeps the original encoded pair, it is cleared once the pair is modified
+	raw string
+}
+
+// OrderedQuery keeps the query pairs in their original order, including the repeated keys.
+// Unlike url.Values, it encodes the untouched pairs exactly as they were parsed.
+type OrderedQuery []QueryPair
+
+// ParseOrderedQuery parses the raw query, the malformed pairs are skipped as url.ParseQuery does.
+// The pairs are only separated by "&", so a pair keeps its literal ";" as RawValues does
+func ParseOrderedQuery(rawQuery string) OrderedQuery {
+	var query OrderedQuery
+
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+
+		rawKey, rawValue := raw, ""
+		if i := strings.IndexByte(raw, '='); i >= 0 {
+			rawKey, rawValue = raw[:i], raw[i+1:]
+		}
+
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			continue
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			continue
+		}
+
+		query = append(query, QueryPair{key, value, raw})
+	}
+
+	return query
+}
+
+// RawValues returns the values of the key in the raw query whose pairs are only separated by "&",
+// so the values keep a literal ";", e.g. the RSQL conjunction "author==jk;year>2000" which url.ParseQuery rejects
+func RawValues(rawQuery, key string) []string {
+	var values []string
+
+	for _, raw := range strings.Split(rawQuery, "&") {
+		rawKey, rawValue := raw, ""
+		if i := strings.IndexByte(raw, '='); i >= 0 {
+			rawKey, rawValue = raw[:i], raw[i+1:]
+		}
+
+		if k, err := url.QueryUnescape(rawKey); err != nil || k != key {
+			continue
+		}
+		if value, err := url.QueryUnescape(rawValue); err == nil {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// Clone returns a copy of the query
+func (q OrderedQuery) Clone() OrderedQuery {
+	return append(OrderedQuery(nil), q...)
+}
+
+// Get returns the first value of the key, it is empty if the key doesn't exist
+func (q OrderedQuery) Get(key string) string {
+	for _, pair := range q {
+		if pair.Key == key {
+			return pair.Value
+		}
+	}
+
+	return ""
+}
+
+// Set replaces the first pair of the key in place and removes its other pairs,
+// the pair is appended if the key doesn't exist
+func (q *OrderedQuery) Set(key, value string) {
+	set := false
+	pairs := (*q)[:0]
+
+	for _, pair := range *q {
+		if pair.Key != key {
+			pairs = append(pairs, pair)
+			continue
+		}
+		if !set {
+			pairs = append(pairs, QueryPair{Key: key, Value: value})
+			set = true
+		}
+	}
+
+	if !set {
+		pairs = append(pairs, QueryPair{Key: key, Value: value})
+	}
+
+	*q = pairs
+}
+
+// Del removes all pairs of the key
+func (q *OrderedQuery) Del(key string) {
+	pairs := (*q)[:0]
+
+	for _, pair := range *q {
+		if pair.Key != key {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	*q = pairs
+}
+
+// Merge replaces the pairs of each key in values whose values differ, in place of the first pair of the key,
+// a key without values is removed and a new key is appended. The keys not in values are kept untouched
+func (q *OrderedQuery) Merge(values url.Values) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		q.replace(key, values[key])
+	}
+}
+
+func (q *OrderedQuery) replace(key string, values []string) {
+	var current []string
+	for _, pair := range *q {
+		if pair.Key == key {
+			current = append(current, pair.Value)
+		}
+	}
+	if equalValues(current, values) {
+		return
+	}
+
+	replaced := false
+	pairs := make(OrderedQuery, 0, len(*q)+len(values))
+
+	for _, pair := range *q {
+		if pair.Key != key {
+			pairs = append(pairs, pair)
+			continue
+		}
+		if !replaced {
+			for _, value := range values {
+				pairs = append(pairs, QueryPair{Key: key, Value: value})
+			}
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		for _, value := range values {
+			pairs = append(pairs, QueryPair{Key: key, Value: value})
+		}
+	}
+
+	*q = pairs
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Values returns the query as url.Values
+func (q OrderedQuery) Values() url.Values {
+	values := url.Values{}
+	for _, pair := range q {
+		values.Add(pair.Key, pair.Value)
+	}
+
+	return values
+}
+
+// Encode encodes the query in its order
+func (q OrderedQuery) Encode() string {
+	terms := make([]string, len(q))
+	for i, pair := range q {
+		if pair.raw != "" {
+			terms[i] = pair.raw
+			continue
+		}
+		terms[i] = url.QueryEscape(pair.Key) + "=" + url.QueryEscape(pair.Value)
+	}
+
+	return strings.Join(terms, "&")
+}
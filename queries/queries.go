@@ -5,13 +5,24 @@ import (
 	"strconv"
 )
 
-// PaginationQueries defines query fields
+// Params defines the names of the pagination parameters
+type Params struct {
+	Page     string
+	PageSize string
+}
+
+// DefaultParams are the pagination parameter names used by ParseLink
+var DefaultParams = Params{Page: "page", PageSize: "page_size"}
+
+// PaginationQueries defines query fields, Ordered and RawQuery keep the original query including the pagination fields
 type PaginationQueries struct {
 	Query      url.Values
 	FirstQuery url.Values
 	LastQuery  url.Values
 	PrevQuery  url.Values
 	NextQuery  url.Values
+	Ordered    OrderedQuery
+	RawQuery   string
 }
 
 func (q *PaginationQueries) initPaginationQueries(u *url.URL) *PaginationQueries {
@@ -20,29 +31,41 @@ func (q *PaginationQueries) initPaginationQueries(u *url.URL) *PaginationQueries
 	q.LastQuery = u.Query()
 	q.PrevQuery = u.Query()
 	q.NextQuery = u.Query()
+	q.Ordered = ParseOrderedQuery(u.RawQuery)
+	q.RawQuery = u.RawQuery
 
 	return q
 }
 
-func (q *PaginationQueries) cleanPaginations() *PaginationQueries {
-	q.Query.Del("page")
-	q.Query.Del("page_size")
-	q.FirstQuery.Del("page")
-	q.FirstQuery.Del("page_size")
-	q.LastQuery.Del("page")
-	q.LastQuery.Del("page_size")
-	q.PrevQuery.Del("page")
-	q.PrevQuery.Del("page_size")
-	q.NextQuery.Del("page")
-	q.NextQuery.Del("page_size")
+func (q *PaginationQueries) cleanPaginations(params Params) *PaginationQueries {
+	q.Query.Del(params.Page)
+	q.Query.Del(params.PageSize)
+	q.FirstQuery.Del(params.Page)
+	q.FirstQuery.Del(params.PageSize)
+	q.LastQuery.Del(params.Page)
+	q.LastQuery.Del(params.PageSize)
+	q.PrevQuery.Del(params.Page)
+	q.PrevQuery.Del(params.PageSize)
+	q.NextQuery.Del(params.Page)
+	q.NextQuery.Del(params.PageSize)
 
 	return q
 }
 
 // ParseLink parse link to infomation components
-func ParseLink(link string, defaultPageSize int) (
+func ParseLink(link string, defaultPageSize int64) (
+	basePath string,
+	page, pageSize int64,
+	queries PaginationQueries,
+	hasPage, hasPageSize bool,
+) {
+	return ParseLinkWithParams(link, defaultPageSize, DefaultParams)
+}
+
+// ParseLinkWithParams does the same thing with ParseLink, and it reads the pagination parameters by the specified names
+func ParseLinkWithParams(link string, defaultPageSize int64, params Params) (
 	basePath string,
-	page, pageSize int,
+	page, pageSize int64,
 	queries PaginationQueries,
 	hasPage, hasPageSize bool,
 ) {
@@ -59,8 +82,8 @@ func ParseLink(link string, defaultPageSize int) (
 	basePath = basePath + parsedURL.Host + parsedURL.Path
 	queries.initPaginationQueries(parsedURL)
 
-	if queryPage := queries.Query.Get("page"); queryPage != "" {
-		if page, err = strconv.Atoi(queryPage); err != nil {
+	if queryPage := queries.Query.Get(params.Page); queryPage != "" {
+		if page, err = strconv.ParseInt(queryPage, 10, 64); err != nil {
 			page = 1
 		} else {
 			hasPage = true
@@ -69,8 +92,8 @@ func ParseLink(link string, defaultPageSize int) (
 		page = 1
 	}
 
-	if queryPageSize := queries.Query.Get("page_size"); queryPageSize != "" {
-		if pageSize, err = strconv.Atoi(queryPageSize); err != nil {
+	if queryPageSize := queries.Query.Get(params.PageSize); queryPageSize != "" {
+		if pageSize, err = strconv.ParseInt(queryPageSize, 10, 64); err != nil {
 			pageSize = defaultPageSize
 		} else {
 			hasPageSize = true
@@ -79,7 +102,7 @@ func ParseLink(link string, defaultPageSize int) (
 		pageSize = defaultPageSize
 	}
 
-	queries.cleanPaginations()
+	queries.cleanPaginations(params)
 
 	return
 }
@@ -2,11 +2,13 @@ package queries
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"testing"
 )
 
 func TestParseLinkFail(t *testing.T) {
-	defaultPageSize := 30
+	defaultPageSize := int64(30)
 	var testLinks = []string{":::", ":", "::/..", "::/.", "::\\"}
 
 	for i, testLink := range testLinks {
@@ -35,15 +37,15 @@ func TestParseLinkFail(t *testing.T) {
 }
 
 func TestParseLink(t *testing.T) {
-	defaultPageSize := 30
+	defaultPageSize := int64(30)
 
 	{
 		var tests = []struct {
 			testName     string
 			link         string
 			basePath     string
-			page         int
-			pageSize     int
+			page         int64
+			pageSize     int64
 			queryEncoded string
 			hasPage      bool
 			hasPageSize  bool
@@ -117,3 +119,79 @@ func TestParseLink(t *testing.T) {
 		}
 	}
 }
+
+func TestOrderedQuery(t *testing.T) {
+	query := ParseOrderedQuery("z=1&page=2&a=x%20y&tag=b&tag=a&page=9&bad=%zz&page_size=5&empty")
+
+	if encoded := query.Encode(); encoded != "z=1&page=2&a=x%20y&tag=b&tag=a&page=9&page_size=5&empty" {
+		t.Errorf("[ordered encode]: got %s", encoded)
+	}
+	if query.Get("a") != "x y" || query.Get("tag") != "b" || query.Get("missing") != "" {
+		t.Errorf("[ordered get]: got a=%s tag=%s", query.Get("a"), query.Get("tag"))
+	}
+
+	cloned := query.Clone()
+	cloned.Set("page", "3")
+	cloned.Set("page_size", "10")
+	cloned.Set("sort", "-year")
+	cloned.Del("z")
+
+	if encoded := cloned.Encode(); encoded != "page=3&a=x%20y&tag=b&tag=a&page_size=10&empty&sort=-year" {
+		t.Errorf("[ordered set]: got %s", encoded)
+	}
+	if encoded := query.Encode(); encoded != "z=1&page=2&a=x%20y&tag=b&tag=a&page=9&page_size=5&empty" {
+		t.Errorf("[ordered clone is independent]: got %s", encoded)
+	}
+	if values := query.Values(); len(values["tag"]) != 2 || values.Get("page") != "2" {
+		t.Errorf("[ordered values]: got %v", values)
+	}
+}
+
+func TestOrderedQueryMerge(t *testing.T) {
+	tests := []struct {
+		testName string
+		values   url.Values
+		encoded  string
+	}{
+		{"same values", url.Values{"a": {"x y"}, "tag": {"b", "a"}}, "z=1&a=x%20y&tag=b&tag=a&f=a;b"},
+		{"changed values", url.Values{"tag": {"c"}, "a": {"x y"}}, "z=1&a=x%20y&tag=c&f=a;b"},
+		{"more values", url.Values{"z": {"1", "2"}}, "z=1&z=2&a=x%20y&tag=b&tag=a&f=a;b"},
+		{"removed key", url.Values{"tag": nil}, "z=1&a=x%20y&f=a;b"},
+		{"new keys", url.Values{"y": {"2"}, "b": {"1"}}, "z=1&a=x%20y&tag=b&tag=a&f=a;b&b=1&y=2"},
+	}
+
+	for i, test := range tests {
+		query := ParseOrderedQuery("z=1&a=x%20y&tag=b&tag=a&f=a;b")
+		query.Merge(test.values)
+
+		if encoded := query.Encode(); encoded != test.encoded {
+			t.Errorf("%d. [%s] merged: got %s, want %s", i, test.testName, encoded, test.encoded)
+		}
+	}
+}
+
+func TestRawValues(t *testing.T) {
+	values := RawValues("filter=author==jk;year>2000&q=x&filter=a%3Db&bad=%zz&filter", "filter")
+
+	if want := []string{"author==jk;year>2000", "a=b", ""}; strings.Join(values, "|") != strings.Join(want, "|") {
+		t.Errorf("[raw values]: got %q, want %q", values, want)
+	}
+	if values := RawValues("q=x", "filter"); values != nil {
+		t.Errorf("[raw values missing]: got %q", values)
+	}
+}
+
+func TestParseLinkWithParams(t *testing.T) {
+	params := Params{Page: "p", PageSize: "per_page"}
+
+	basePath, page, pageSize, queries, hasPage, hasPageSize := ParseLinkWithParams(
+		"api.example.com/books?author=jk&p=3&per_page=10&page=9", 30, params,
+	)
+
+	if basePath != "api.example.com/books" || page != 3 || pageSize != 10 || !hasPage || !hasPageSize {
+		t.Errorf("[custom params]: got %s, %d, %d, %v, %v", basePath, page, pageSize, hasPage, hasPageSize)
+	}
+	if encoded := queries.NextQuery.Encode(); encoded != "author=jk&page=9" {
+		t.Errorf("[custom params cleaned]: got %s, want %s", encoded, "author=jk&page=9")
+	}
+}
@@ -0,0 +1,118 @@
+package pagination
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RangeError is returned when a Range header can't be parsed as an item range, or can't be served as a page
+type RangeError struct {
+	Header    string
+	Unaligned bool
+}
+
+func (e *RangeError) Error() string {
+	if e.Unaligned {
+		return fmt.Sprintf("pagination: range %q doesn't start at a page of its length", e.Header)
+	}
+
+	return fmt.Sprintf("pagination: invalid range %q", e.Header)
+}
+
+// ParseRange resets page and pageSize by a Range request header, e.g. `items=0-24`.
+// The range length decides pageSize, and the range must start at a page of that size.
+// An open range, e.g. `items=50-`, keeps the current pageSize.
+// An empty header is ignored, an invalid one is reported by the error and leaves page and pageSize untouched.
+// An unaligned range, e.g. `items=5-14`, is reported by an Unaligned *RangeError,
+// and ContentRange answers it by 416 Range Not Satisfiable.
+// Each call forgets whether the previous header was a range or an unaligned one.
+func (p *Paginator) ParseRange(header string) error {
+	p.hasRange, p.unalignedRange = false, false
+
+	if header == "" {
+		return nil
+	}
+
+	spec := strings.TrimPrefix(header, p.rangeUnit+"=")
+	dash := strings.IndexByte(spec, '-')
+	if spec == header || dash <= 0 || strings.IndexByte(spec, ',') >= 0 {
+		return &RangeError{Header: header}
+	}
+
+	first, err := strconv.ParseInt(strings.TrimSpace(spec[:dash]), 10, 64)
+	if err != nil || first < 0 {
+		return &RangeError{Header: header}
+	}
+
+	last := first + p.pager.GetNavigation().PageSize - 1
+	if rawLast := strings.TrimSpace(spec[dash+1:]); rawLast != "" {
+		if last, err = strconv.ParseInt(rawLast, 10, 64); err != nil || last < first {
+			return &RangeError{Header: header}
+		}
+	}
+
+	if length := last - first + 1; first%length != 0 {
+		p.hasRange, p.unalignedRange = true, true
+		return &RangeError{Header: header, Unaligned: true}
+	}
+
+	p.pager.SetRange(first, last)
+	p.hasRange = true
+	p.hasPage = true
+	p.hasPageSize = true
+
+	return nil
+}
+
+// HasRawRange returns whether a Range header has been parsed into the Paginator, an unaligned range included
+func (p *Paginator) HasRawRange() bool {
+	return p.hasRange
+}
+
+// ContentRange returns the Content-Range header value and the response status by the Paginator context and total:
+//
+// -- 206 Partial Content, when a Range header is parsed and the served items are part of the list
+//
+// -- 200 OK, when no Range header is parsed, the list is empty or all of it is served
+//
+// -- 416 Range Not Satisfiable, when the range starts beyond the total or isn't aligned to a page
+func (p *Paginator) ContentRange(total int64) (contentRange string, status int) {
+	p.pager.SetTotal(total)
+	start, end := p.GetRange()
+
+	if p.unalignedRange {
+		return fmt.Sprintf("%s */%d", p.rangeUnit, total), http.StatusRequestedRangeNotSatisfiable
+	}
+
+	if total <= 0 {
+		return fmt.Sprintf("%s */0", p.rangeUnit), http.StatusOK
+	}
+
+	if start >= total {
+		if !p.hasRange {
+			return fmt.Sprintf("%s */%d", p.rangeUnit, total), http.StatusOK
+		}
+		return fmt.Sprintf("%s */%d", p.rangeUnit, total), http.StatusRequestedRangeNotSatisfiable
+	}
+
+	contentRange = fmt.Sprintf("%s %d-%d/%d", p.rangeUnit, start, end-1, total)
+	if !p.hasRange || start == 0 && end >= total {
+		return contentRange, http.StatusOK
+	}
+
+	return contentRange, http.StatusPartialContent
+}
+
+// WriteRangeHeaders sets Content-Range and Accept-Ranges headers, writes the status and returns it.
+// It should be called before writing the response body
+func (p *Paginator) WriteRangeHeaders(w http.ResponseWriter, total int64) int {
+	contentRange, status := p.ContentRange(total)
+
+	w.Header().Set("Accept-Ranges", p.rangeUnit)
+	w.Header().Set("Content-Range", contentRange)
+	w.WriteHeader(status)
+
+	return status
+}
@@ -0,0 +1,87 @@
+package pagination_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		testName     string
+		header       string
+		total        int64
+		start, end   int64
+		contentRange string
+		status       int
+		hasErr       bool
+	}{
+		{"first range", "items=0-4", 20, 0, 5, "items 0-4/20", http.StatusPartialContent, false},
+		{"middle range", "items=5-9", 20, 5, 10, "items 5-9/20", http.StatusPartialContent, false},
+		{"last partial range", "items=10-19", 15, 10, 15, "items 10-14/15", http.StatusPartialContent, false},
+		{"unaligned range", "items=7-11", 20, 5, 10, "items */20", http.StatusRequestedRangeNotSatisfiable, true},
+		{"unaligned last range", "items=15-24", 20, 5, 10, "items */20", http.StatusRequestedRangeNotSatisfiable, true},
+		{"unaligned open range", "items=7-", 20, 5, 10, "items */20", http.StatusRequestedRangeNotSatisfiable, true},
+		{"open range", "items=10-", 20, 10, 15, "items 10-14/20", http.StatusPartialContent, false},
+		{"whole list", "items=0-49", 20, 0, 20, "items 0-19/20", http.StatusOK, false},
+		{"beyond total", "items=40-44", 20, 20, 20, "items */20", http.StatusRequestedRangeNotSatisfiable, false},
+		{"empty list", "items=0-4", 0, 0, 5, "items */0", http.StatusOK, false},
+		{"no header", "", 20, 5, 10, "items 5-9/20", http.StatusOK, false},
+		{"wrong unit", "bytes=0-4", 20, 5, 10, "items 5-9/20", http.StatusOK, true},
+		{"suffix range", "items=-5", 20, 5, 10, "items 5-9/20", http.StatusOK, true},
+		{"reversed range", "items=9-5", 20, 5, 10, "items 5-9/20", http.StatusOK, true},
+		{"multiple ranges", "items=0-4,10-14", 20, 5, 10, "items 5-9/20", http.StatusOK, true},
+	}
+
+	for i, test := range tests {
+		pgt := pagination.DefaultPagination().Parse(requestURI)
+
+		err := pgt.ParseRange(test.header)
+		if (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+		}
+
+		w := httptest.NewRecorder()
+		status := pgt.WriteRangeHeaders(w, test.total)
+		start, end := pgt.GetRange()
+
+		if start != test.start || end != test.end {
+			t.Errorf("%d. [%s] range: got (%d, %d), want (%d, %d)", i, test.testName, start, end, test.start, test.end)
+		}
+		if status != test.status || w.Code != test.status {
+			t.Errorf("%d. [%s] status: got %d, want %d", i, test.testName, status, test.status)
+		}
+		if contentRange := w.Header().Get("Content-Range"); contentRange != test.contentRange {
+			t.Errorf("%d. [%s] Content-Range: got %s, want %s", i, test.testName, contentRange, test.contentRange)
+		}
+		if acceptRanges := w.Header().Get("Accept-Ranges"); acceptRanges != "items" {
+			t.Errorf("%d. [%s] Accept-Ranges: got %s, want items", i, test.testName, acceptRanges)
+		}
+	}
+
+	reparsed := []struct {
+		testName string
+		header   string
+		hasRange bool
+		status   int
+	}{
+		{"aligned after unaligned", "items=0-4", true, http.StatusPartialContent},
+		{"no header after unaligned", "", false, http.StatusOK},
+		{"invalid after unaligned", "items=-5", false, http.StatusOK},
+	}
+
+	for i, test := range reparsed {
+		pgt := pagination.DefaultPagination().Parse(requestURI)
+		pgt.ParseRange("items=7-11")
+		pgt.ParseRange(test.header)
+
+		if pgt.HasRawRange() != test.hasRange {
+			t.Errorf("%d. [%s] has range: got %v, want %v", i, test.testName, pgt.HasRawRange(), test.hasRange)
+		}
+		if status := pgt.WriteRangeHeaders(httptest.NewRecorder(), 20); status != test.status {
+			t.Errorf("%d. [%s] status: got %d, want %d", i, test.testName, status, test.status)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package pagination
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zheeeng/pagination/fieldsets"
+	"github.com/zheeeng/pagination/sorting"
+	"github.com/zheeeng/pagination/uritemplate"
+)
+
+// DefaultProfile is the profile used by Registry::Parse when no route matches the link
+const DefaultProfile = "default"
+
+// ConfigurationError is returned when a configuration is invalid
+type ConfigurationError struct {
+	Profile string
+	Field   string
+	Reason  string
+}
+
+func (e *ConfigurationError) Error() string {
+	if e.Profile != "" {
+		return fmt.Sprintf("pagination: profile %q: %s %s", e.Profile, e.Field, e.Reason)
+	}
+
+	return fmt.Sprintf("pagination: %s %s", e.Field, e.Reason)
+}
+
+// Validate checks the configuration, it is meant to be called at startup
+func (cfg PaginatorConfiguration) Validate() error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cfg PaginatorConfiguration) validate() *ConfigurationError {
+	if cfg.PageSize < 0 {
+		return &ConfigurationError{Field: "page_size", Reason: "must not be negative"}
+	}
+	if cfg.MaxPageSize < 0 {
+		return &ConfigurationError{Field: "max_page_size", Reason: "must not be negative"}
+	}
+
+	cfg = cfg.withDefaults()
+
+	params := map[string]string{}
+	for _, param := range [][2]string{
+		{"page_param", cfg.PageParam},
+		{"page_size_param", cfg.PageSizeParam},
+		{"sort.param", sortParam(cfg.Sort)},
+		{"filter.param", filterParam(cfg.Filter)},
+		{"fieldset.param", fieldsetParam(cfg.Fieldset)},
+	} {
+		if param[1] == "" {
+			continue
+		}
+		if field, ok := params[param[1]]; ok {
+			return &ConfigurationError{Field: param[0], Reason: fmt.Sprintf("conflicts with %s %q", field, param[1])}
+		}
+		params[param[1]] = param[0]
+	}
+
+	switch cfg.Envelope {
+	case EnvelopeDefault, EnvelopeDRF, EnvelopeSpring, EnvelopeLaravel:
+	default:
+		return &ConfigurationError{Field: "envelope", Reason: fmt.Sprintf("is unknown: %q", cfg.Envelope)}
+	}
+	if _, err := cfg.OutOfRange.MarshalText(); err != nil {
+		return &ConfigurationError{Field: "out_of_range", Reason: fmt.Sprintf("is unknown: %d", cfg.OutOfRange)}
+	}
+	if _, err := cfg.Links.Format.MarshalText(); err != nil {
+		return &ConfigurationError{Field: "links.format", Reason: fmt.Sprintf("is unknown: %d", cfg.Links.Format)}
+	}
+	if cfg.Sort != nil && cfg.Sort.Default != "" {
+		if _, err := sorting.Parse(cfg.Sort.Default, cfg.Sort.Fields); err != nil {
+			return &ConfigurationError{Field: "sort.default", Reason: err.Error()}
+		}
+	}
+	if cfg.Links.Template != "" {
+		if _, err := uritemplate.Parse(cfg.Links.Template); err != nil {
+			return &ConfigurationError{Field: "links.template", Reason: err.Error()}
+		}
+	}
+	if cfg.PathPattern != "" {
+		if _, err := parsePathPattern(cfg.PathPattern); err != nil {
+			return &ConfigurationError{Field: "path_pattern", Reason: err.Error()}
+		}
+	}
+	if cfg.Fieldset != nil && cfg.Fieldset.Default != "" {
+		if _, err := fieldsets.Parse(cfg.Fieldset.Default, cfg.Fieldset.Fields); err != nil {
+			return &ConfigurationError{Field: "fieldset.default", Reason: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+func sortParam(cfg *SortConfiguration) string {
+	if cfg == nil {
+		return ""
+	}
+
+	return cfg.Param
+}
+
+func filterParam(cfg *FilterConfiguration) string {
+	if cfg == nil {
+		return ""
+	}
+
+	return cfg.Param
+}
+
+func fieldsetParam(cfg *FieldsetConfiguration) string {
+	if cfg == nil {
+		return ""
+	}
+
+	return cfg.Param
+}
+
+// RegistryConfiguration is the declarative form of a Registry, it is the shape of the configuration files, e.g.
+//
+//	{
+//	    "profiles": {
+//	        "default": {"page_size": 30},
+//	        "books": {"page_size": 50, "max_page_size": 200, "envelope": "drf"}
+//	    },
+//	    "routes": [
+//	        {"pattern": "/books/*", "profile": "books"}
+//	    ]
+//	}
+type RegistryConfiguration struct {
+	Profiles map[string]PaginatorConfiguration `json:"profiles"`
+	Routes   []RouteConfiguration              `json:"routes,omitempty"`
+}
+
+// RouteConfiguration maps a path pattern to a profile.
+// The pattern segments are matched literally, except "{name}" and ":name" match any segment,
+// and a trailing "*" matches the rest of the path, e.g. "/shops/{shop}/books/*"
+type RouteConfiguration struct {
+	Pattern string `json:"pattern"`
+	Profile string `json:"profile"`
+}
+
+// The policies and the link formats are written by their names in the configuration files
+var (
+	outOfRangeNames = []string{"empty", "clamp", "redirect", "not_found"}
+	linkFormatNames = []string{"absolute", "root_relative", "query_only"}
+)
+
+func marshalName(names []string, value int, kind string) ([]byte, error) {
+	if value < 0 || value >= len(names) {
+		return nil, fmt.Errorf("pagination: unknown %s %d", kind, value)
+	}
+
+	return []byte(names[value]), nil
+}
+
+func unmarshalName(names []string, text []byte, kind string) (int, error) {
+	for i, name := range names {
+		if name == string(text) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("pagination: unknown %s %q", kind, text)
+}
+
+// MarshalText encodes the policy by its name, e.g. "clamp"
+func (policy OutOfRangePolicy) MarshalText() ([]byte, error) {
+	return marshalName(outOfRangeNames, int(policy), "out-of-range policy")
+}
+
+// UnmarshalText decodes the policy from its name
+func (policy *OutOfRangePolicy) UnmarshalText(text []byte) error {
+	value, err := unmarshalName(outOfRangeNames, text, "out-of-range policy")
+	if err == nil {
+		*policy = OutOfRangePolicy(value)
+	}
+
+	return err
+}
+
+// MarshalText encodes the format by its name, e.g. "root_relative"
+func (format LinkFormat) MarshalText() ([]byte, error) {
+	return marshalName(linkFormatNames, int(format), "link format")
+}
+
+// UnmarshalText decodes the format from its name
+func (format *LinkFormat) UnmarshalText(text []byte) error {
+	value, err := unmarshalName(linkFormatNames, text, "link format")
+	if err == nil {
+		*format = LinkFormat(value)
+	}
+
+	return err
+}
+
+// Decoder decodes a configuration file into v, e.g. json.Unmarshal or yaml.Unmarshal
+type Decoder func(data []byte, v interface{}) error
+
+var decoders = struct {
+	sync.RWMutex
+	byExt map[string]Decoder
+}{
+	byExt: map[string]Decoder{".json": json.Unmarshal},
+}
+
+// RegisterDecoder registers the decoder of the configuration files with the extension, e.g. ".yaml".
+// Only JSON is decoded out of the box, plug in the YAML or TOML decoder of your choice.
+// The decoder must honor the `json` struct tags, e.g. the one of sigs.k8s.io/yaml:
+//
+//	pagination.RegisterDecoder(".yaml", yaml.Unmarshal)
+func RegisterDecoder(ext string, decode Decoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+
+	decoders.byExt[strings.ToLower(ext)] = decode
+}
+
+type route struct {
+	RouteConfiguration
+	segments []string
+}
+
+func (rt route) match(segments []string) bool {
+	for i, segment := range rt.segments {
+		if segment == "*" && i == len(rt.segments)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if isPatternVariable(segment) {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+
+	return len(segments) == len(rt.segments)
+}
+
+func isPatternVariable(segment string) bool {
+	return strings.HasPrefix(segment, ":") ||
+		strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func splitSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+// Registry holds named pagination profiles and looks them up by route patterns.
+// It is safe for concurrent use.
+type Registry struct {
+	mu          sync.RWMutex
+	profiles    map[string]PaginatorConfiguration
+	paginations map[string]Pagination
+	routes      []route
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		profiles:    map[string]PaginatorConfiguration{},
+		paginations: map[string]Pagination{},
+	}
+}
+
+func validateProfile(name string, cfg PaginatorConfiguration) error {
+	if name == "" {
+		return &ConfigurationError{Field: "profile", Reason: "name is empty"}
+	}
+	if err := cfg.validate(); err != nil {
+		err.Profile = name
+		return err
+	}
+
+	return nil
+}
+
+func newRoute(pattern, profile string) route {
+	return route{
+		RouteConfiguration: RouteConfiguration{Pattern: pattern, Profile: profile},
+		segments:           splitSegments(pattern),
+	}
+}
+
+func unknownProfileError(pattern, profile string) error {
+	return &ConfigurationError{Profile: profile, Field: "route " + strconv.Quote(pattern), Reason: "refers to an unknown profile"}
+}
+
+// Register validates the configuration and stores it under the profile name, it replaces the existing profile
+func (r *Registry) Register(name string, cfg PaginatorConfiguration) error {
+	if err := validateProfile(name, cfg); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.profiles[name] = cfg
+	r.paginations[name] = NewPagination(cfg)
+
+	return nil
+}
+
+// Route maps the path pattern to a registered profile, the patterns are tried in the order they are routed
+func (r *Registry) Route(pattern, profile string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.profiles[profile]; !ok {
+		return unknownProfileError(pattern, profile)
+	}
+
+	r.routes = append(r.routes, newRoute(pattern, profile))
+
+	return nil
+}
+
+// Apply registers the profiles and then the routes of the configuration.
+// All of them are validated first, the profiles in name order, and nothing is registered if any of them is invalid.
+func (r *Registry) Apply(cfg RegistryConfiguration) error {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paginations := make(map[string]Pagination, len(names))
+	for _, name := range names {
+		if err := validateProfile(name, cfg.Profiles[name]); err != nil {
+			return err
+		}
+		paginations[name] = NewPagination(cfg.Profiles[name])
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	routes := make([]route, 0, len(cfg.Routes))
+	for _, rt := range cfg.Routes {
+		_, applied := cfg.Profiles[rt.Profile]
+		if _, registered := r.profiles[rt.Profile]; !applied && !registered {
+			return unknownProfileError(rt.Pattern, rt.Profile)
+		}
+		routes = append(routes, newRoute(rt.Pattern, rt.Profile))
+	}
+
+	for _, name := range names {
+		r.profiles[name] = cfg.Profiles[name]
+		r.paginations[name] = paginations[name]
+	}
+	r.routes = append(r.routes, routes...)
+
+	return nil
+}
+
+// Load decodes a configuration from the reader and applies it
+func (r *Registry) Load(reader io.Reader, decode Decoder) error {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	var cfg RegistryConfiguration
+	if err := decode(data, &cfg); err != nil {
+		return fmt.Errorf("pagination: decode configuration: %v", err)
+	}
+
+	return r.Apply(cfg)
+}
+
+// LoadFile loads a configuration file, the decoder is chosen by the file extension, see RegisterDecoder
+func (r *Registry) LoadFile(path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	decoders.RLock()
+	decode, ok := decoders.byExt[ext]
+	decoders.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("pagination: no decoder registered for %q files", ext)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return r.Load(file, decode)
+}
+
+// envSeparator separates the profile name from the field in the environment variable names
+const envSeparator = "__"
+
+// envFields lists the environment variable names of the profile fields
+var envFields = map[string]bool{
+	"PAGE_SIZE":            true,
+	"MAX_PAGE_SIZE":        true,
+	"PAGE_PARAM":           true,
+	"PAGE_SIZE_PARAM":      true,
+	"PATH_PATTERN":         true,
+	"NAMESPACE":            true,
+	"ENVELOPE":             true,
+	"RANGE_UNIT":           true,
+	"ZERO_BASED_PAGE":      true,
+	"OUT_OF_RANGE":         true,
+	"PRESERVE_QUERY_ORDER": true,
+	"LINKS_BASE_URL":       true,
+	"LINKS_STRIP_PREFIX":   true,
+	"LINKS_PATH_PREFIX":    true,
+	"LINKS_TEMPLATE":       true,
+	"LINKS_FORMAT":         true,
+	"SORT_PARAM":           true,
+	"SORT_FIELDS":          true,
+	"SORT_DEFAULT":         true,
+	"FIELDSET_PARAM":       true,
+	"FIELDSET_FIELDS":      true,
+	"FIELDSET_DEFAULT":     true,
+	"FILTER_PARAM":         true,
+	"FILTER_BRACKETS":      true,
+}
+
+func setEnvField(cfg *PaginatorConfiguration, field, value string) error {
+	var err error
+
+	switch field {
+	case "PAGE_SIZE":
+		cfg.PageSize, err = strconv.ParseInt(value, 10, 64)
+	case "MAX_PAGE_SIZE":
+		cfg.MaxPageSize, err = strconv.ParseInt(value, 10, 64)
+	case "PAGE_PARAM":
+		cfg.PageParam = value
+	case "PAGE_SIZE_PARAM":
+		cfg.PageSizeParam = value
+	case "PATH_PATTERN":
+		cfg.PathPattern = value
+	case "NAMESPACE":
+		cfg.Namespace = value
+	case "ENVELOPE":
+		cfg.Envelope = Envelope(strings.ToLower(value))
+	case "RANGE_UNIT":
+		cfg.RangeUnit = value
+	case "ZERO_BASED_PAGE":
+		cfg.ZeroBasedPage, err = strconv.ParseBool(value)
+	case "OUT_OF_RANGE":
+		err = cfg.OutOfRange.UnmarshalText([]byte(strings.ToLower(value)))
+	case "PRESERVE_QUERY_ORDER":
+		cfg.PreserveQueryOrder, err = strconv.ParseBool(value)
+	case "LINKS_BASE_URL":
+		cfg.Links.BaseURL = value
+	case "LINKS_STRIP_PREFIX":
+		cfg.Links.StripPrefix = value
+	case "LINKS_PATH_PREFIX":
+		cfg.Links.PathPrefix = value
+	case "LINKS_TEMPLATE":
+		cfg.Links.Template = value
+	case "LINKS_FORMAT":
+		err = cfg.Links.Format.UnmarshalText([]byte(strings.ToLower(value)))
+	case "SORT_PARAM", "SORT_FIELDS", "SORT_DEFAULT":
+		sortCfg := SortConfiguration{}
+		if cfg.Sort != nil {
+			sortCfg = *cfg.Sort
+		}
+		switch field {
+		case "SORT_PARAM":
+			sortCfg.Param = value
+		case "SORT_FIELDS":
+			sortCfg.Fields = splitList(value)
+		case "SORT_DEFAULT":
+			sortCfg.Default = value
+		}
+		cfg.Sort = &sortCfg
+	case "FIELDSET_PARAM", "FIELDSET_FIELDS", "FIELDSET_DEFAULT":
+		fieldsetCfg := FieldsetConfiguration{}
+		if cfg.Fieldset != nil {
+			fieldsetCfg = *cfg.Fieldset
+		}
+		switch field {
+		case "FIELDSET_PARAM":
+			fieldsetCfg.Param = value
+		case "FIELDSET_FIELDS":
+			fieldsetCfg.Fields = splitList(value)
+		case "FIELDSET_DEFAULT":
+			fieldsetCfg.Default = value
+		}
+		cfg.Fieldset = &fieldsetCfg
+	case "FILTER_PARAM", "FILTER_BRACKETS":
+		filterCfg := FilterConfiguration{}
+		if cfg.Filter != nil {
+			filterCfg = *cfg.Filter
+		}
+		if field == "FILTER_PARAM" {
+			filterCfg.Param = value
+		} else {
+			filterCfg.Brackets, err = strconv.ParseBool(value)
+		}
+		cfg.Filter = &filterCfg
+	}
+
+	return err
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// LoadEnv overrides and adds profiles and routes from the environment variables, e.g. os.Environ().
+// The variables are named "<prefix>_<PROFILE>__<FIELD>", e.g. "PAGINATION_ADMIN_USERS__MAX_PAGE_SIZE=200",
+// the profile name is lowercased and separated from the field by a double underscore. The routes are listed in "<prefix>_ROUTES", e.g. "/books/*=books,/authors/{id}=authors".
+// The filter schema can't be set by the environment variables.
+func (r *Registry) LoadEnv(prefix string, environ []string) error {
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	r.mu.RLock()
+	profiles := make(map[string]PaginatorConfiguration, len(r.profiles))
+	for name, cfg := range r.profiles {
+		profiles[name] = cfg
+	}
+	r.mu.RUnlock()
+
+	var routes []RouteConfiguration
+
+	for _, variable := range environ {
+		eq := strings.IndexByte(variable, '=')
+		if eq < 0 || !strings.HasPrefix(variable, prefix) {
+			continue
+		}
+		key, value := variable[len(prefix):eq], variable[eq+1:]
+
+		if key == "ROUTES" {
+			for _, item := range splitList(value) {
+				sep := strings.LastIndexByte(item, '=')
+				if sep < 0 {
+					return &ConfigurationError{Field: prefix + "ROUTES", Reason: fmt.Sprintf("has an invalid route %q", item)}
+				}
+				routes = append(routes, RouteConfiguration{Pattern: item[:sep], Profile: strings.ToLower(item[sep+1:])})
+			}
+			continue
+		}
+
+		// the profile name ends at the last double underscore, so it may hold single underscores
+		sep := strings.LastIndex(key, envSeparator)
+		if sep < 0 {
+			continue
+		}
+		name, field := strings.ToLower(key[:sep]), key[sep+len(envSeparator):]
+		if name == "" || !envFields[field] {
+			return &ConfigurationError{Profile: name, Field: prefix + key, Reason: "isn't a profile field variable"}
+		}
+
+		cfg := profiles[name]
+		if err := setEnvField(&cfg, field, value); err != nil {
+			return &ConfigurationError{Profile: name, Field: prefix + key, Reason: fmt.Sprintf("has an invalid value %q", value)}
+		}
+		profiles[name] = cfg
+	}
+
+	changed := RegistryConfiguration{Profiles: map[string]PaginatorConfiguration{}, Routes: routes}
+	for name, cfg := range profiles {
+		changed.Profiles[name] = cfg
+	}
+
+	return r.Apply(changed)
+}
+
+// Profile returns the pagination of the profile
+func (r *Registry) Profile(name string) (Pagination, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pg, ok := r.paginations[name]
+	return pg, ok
+}
+
+// Configuration returns the configuration of the profile as it was registered
+func (r *Registry) Configuration(name string) (PaginatorConfiguration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cfg, ok := r.profiles[name]
+	return cfg, ok
+}
+
+// Lookup returns the profile name and pagination of the first route matching the path
+func (r *Registry) Lookup(path string) (profile string, pg Pagination, ok bool) {
+	segments := splitSegments(path)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rt := range r.routes {
+		if rt.match(segments) {
+			return rt.Profile, r.paginations[rt.Profile], true
+		}
+	}
+
+	return "", nil, false
+}
+
+// Parse parses the link by the profile routed from its path,
+// it falls back to the DefaultProfile and then to DefaultPagination
+func (r *Registry) Parse(link string) *Paginator {
+	basePath := link
+	if i := strings.IndexAny(basePath, "?#"); i >= 0 {
+		basePath = basePath[:i]
+	}
+	_, path := splitBasePath(basePath)
+
+	if _, pg, ok := r.Lookup(path); ok {
+		return pg.Parse(link)
+	}
+	if pg, ok := r.Profile(DefaultProfile); ok {
+		return pg.Parse(link)
+	}
+
+	return DefaultPagination().Parse(link)
+}
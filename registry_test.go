@@ -0,0 +1,267 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zheeeng/pagination"
+)
+
+const registryConfiguration = `{
+	"profiles": {
+		"default": {"page_size": 10},
+		"books": {
+			"page_size": 5,
+			"max_page_size": 8,
+			"page_param": "p",
+			"page_size_param": "per_page",
+			"envelope": "drf",
+			"out_of_range": "clamp",
+			"links": {"format": "root_relative"},
+			"sort": {"fields": ["id", "name"], "default": "-id"},
+			"filter": {"schema": {"id": {"type": "int", "operators": ["=gt="]}}}
+		},
+		"authors": {"page_size": 3}
+	},
+	"routes": [
+		{"pattern": "/authors/{id}/books", "profile": "books"},
+		{"pattern": "/authors/*", "profile": "authors"},
+		{"pattern": "/books/:id", "profile": "authors"},
+		{"pattern": "/books", "profile": "books"}
+	]
+}`
+
+func TestRegistryLookup(t *testing.T) {
+	registry := pagination.NewRegistry()
+	if err := registry.Load(strings.NewReader(registryConfiguration), json.Unmarshal); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		testName string
+		path     string
+		profile  string
+		ok       bool
+	}{
+		{"literal", "/books", "books", true},
+		{"trailing slash", "/books/", "books", true},
+		{"colon variable", "/books/7", "authors", true},
+		{"brace variable", "/authors/7/books", "books", true},
+		{"wildcard", "/authors/7/articles/1", "authors", true},
+		{"empty wildcard", "/authors", "authors", true},
+		{"too deep", "/books/7/pages", "", false},
+		{"unrouted", "/shops", "", false},
+	}
+
+	for i, test := range tests {
+		profile, pg, ok := registry.Lookup(test.path)
+		if profile != test.profile || ok != test.ok || (pg != nil) != test.ok {
+			t.Errorf("%d. [%s] lookup: got %q, %v, want %q, %v", i, test.testName, profile, ok, test.profile, test.ok)
+		}
+	}
+}
+
+func TestRegistryParse(t *testing.T) {
+	registry := pagination.NewRegistry()
+	if err := registry.Load(strings.NewReader(registryConfiguration), json.Unmarshal); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		testName string
+		link     string
+		pageSize int64
+		body     string
+	}{
+		{"max page size", "api.example.com/books?p=2&per_page=50", 8, `"next":"/books?p=3\u0026per_page=8\u0026sort=-id"`},
+		{"param names", "api.example.com/books?page=2&per_page=4&filter=id=gt=3", 4, `"count":20`},
+		{"default profile", "api.example.com/shops?page=2", 10, `"page_size":10`},
+		{"routed profile", "api.example.com/authors/1/articles", 3, `"page_size":3`},
+	}
+
+	for i, test := range tests {
+		pgt := registry.Parse(test.link)
+		paginated := pgt.WrapWithTruncate(TrunctableBooks(books), total)
+
+		if paginated.Pagination.PageSize != test.pageSize {
+			t.Errorf("%d. [%s] page size: got %d, want %d", i, test.testName, paginated.Pagination.PageSize, test.pageSize)
+		}
+
+		body, err := json.Marshal(paginated)
+		if err != nil {
+			t.Fatalf("%d. [%s] marshal: %v", i, test.testName, err)
+		}
+		if !strings.Contains(string(body), test.body) {
+			t.Errorf("%d. [%s] body: got %s, want it contains %s", i, test.testName, body, test.body)
+		}
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	tests := []struct {
+		testName string
+		cfg      pagination.PaginatorConfiguration
+		hasErr   bool
+	}{
+		{"zero value", pagination.PaginatorConfiguration{}, false},
+		{"negative page size", pagination.PaginatorConfiguration{PageSize: -1}, true},
+		{"negative max page size", pagination.PaginatorConfiguration{MaxPageSize: -1}, true},
+		{"same params", pagination.PaginatorConfiguration{PageParam: "page_size"}, true},
+		{"sort param conflict", pagination.PaginatorConfiguration{Sort: &pagination.SortConfiguration{Param: "page"}}, true},
+		{"unknown envelope", pagination.PaginatorConfiguration{Envelope: "hal"}, true},
+		{"unknown policy", pagination.PaginatorConfiguration{OutOfRange: 9}, true},
+		{"unknown link format", pagination.PaginatorConfiguration{Links: pagination.LinkConfiguration{Format: 9}}, true},
+		{"unsortable default", pagination.PaginatorConfiguration{Sort: &pagination.SortConfiguration{Fields: []string{"id"}, Default: "name"}}, true},
+	}
+
+	for i, test := range tests {
+		err := pagination.NewRegistry().Register("test", test.cfg)
+		if (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+		}
+		if e, ok := err.(*pagination.ConfigurationError); err != nil && (!ok || e.Profile != "test") {
+			t.Errorf("%d. [%s] error: got %#v, want a *ConfigurationError of the profile", i, test.testName, err)
+		}
+	}
+
+	if err := pagination.NewRegistry().Route("/books", "missing"); err == nil {
+		t.Errorf("route to an unknown profile: expects an error")
+	}
+	if err := pagination.NewRegistry().Load(strings.NewReader(`{"profiles": {"a": {"out_of_range": "nope"}}}`), json.Unmarshal); err == nil {
+		t.Errorf("load an unknown policy: expects an error")
+	}
+}
+
+func TestRegistryApply(t *testing.T) {
+	tests := []struct {
+		testName string
+		cfg      pagination.RegistryConfiguration
+	}{
+		{"invalid profile", pagination.RegistryConfiguration{
+			Profiles: map[string]pagination.PaginatorConfiguration{
+				"a": {PageSize: 20}, "b": {PageSize: -1}, "c": {PageSize: 20}, "default": {PageSize: 20},
+			},
+		}},
+		{"unknown route profile", pagination.RegistryConfiguration{
+			Profiles: map[string]pagination.PaginatorConfiguration{"a": {PageSize: 20}},
+			Routes:   []pagination.RouteConfiguration{{Pattern: "/a", Profile: "a"}, {Pattern: "/b", Profile: "missing"}},
+		}},
+	}
+
+	for i, test := range tests {
+		registry := pagination.NewRegistry()
+		if err := registry.Register("default", pagination.PaginatorConfiguration{PageSize: 10}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := registry.Apply(test.cfg); err == nil {
+			t.Errorf("%d. [%s] expects an error", i, test.testName)
+		}
+		if cfg, _ := registry.Configuration("default"); cfg.PageSize != 10 {
+			t.Errorf("%d. [%s] default page size: got %d, want unchanged 10", i, test.testName, cfg.PageSize)
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			if _, ok := registry.Profile(name); ok {
+				t.Errorf("%d. [%s] profile %s: expects nothing registered", i, test.testName, name)
+			}
+		}
+		if _, _, ok := registry.Lookup("/a"); ok {
+			t.Errorf("%d. [%s] route /a: expects nothing registered", i, test.testName)
+		}
+	}
+
+	registry := pagination.NewRegistry()
+	err := registry.Apply(pagination.RegistryConfiguration{
+		Profiles: map[string]pagination.PaginatorConfiguration{"a": {PageSize: -1}, "b": {PageSize: -1}},
+	})
+	if e, ok := err.(*pagination.ConfigurationError); !ok || e.Profile != "a" {
+		t.Errorf("apply invalid profiles: got %v, want the error of the first profile in name order", err)
+	}
+}
+
+func TestRegistryLoadEnv(t *testing.T) {
+	registry := pagination.NewRegistry()
+	if err := registry.Load(strings.NewReader(registryConfiguration), json.Unmarshal); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	err := registry.LoadEnv("PAGINATION", []string{
+		"PATH=/usr/bin",
+		"PAGINATION_BOOKS__MAX_PAGE_SIZE=20",
+		"PAGINATION_BOOKS__PAGE_SIZE_PARAM=size",
+		"PAGINATION_ADMIN_USERS__PAGE_SIZE=100",
+		"PAGINATION_ADMIN_USERS__OUT_OF_RANGE=not_found",
+		"PAGINATION_ADMIN_USERS__SORT_FIELDS=id, email",
+		"PAGINATION_ADMIN_USERS__LINKS_FORMAT=QUERY_ONLY",
+		"PAGINATION_ADMIN__PAGE_SIZE=7",
+		"PAGINATION_ROUTES=/admin/users/*=admin_users",
+		"PAGINATION_UNRELATED=1",
+	})
+	if err != nil {
+		t.Fatalf("load env: %v", err)
+	}
+
+	books, _ := registry.Configuration("books")
+	if books.MaxPageSize != 20 || books.PageSizeParam != "size" || books.PageParam != "p" || books.PageSize != 5 {
+		t.Errorf("books: got %+v, want the file profile with overridden max page size and page size param", books)
+	}
+
+	users, ok := registry.Configuration("admin_users")
+	if !ok || users.PageSize != 100 || users.OutOfRange != pagination.OutOfRangeNotFound ||
+		users.Sort == nil || strings.Join(users.Sort.Fields, ",") != "id,email" || users.Links.Format != pagination.LinkQueryOnly {
+		t.Errorf("admin_users: got %+v", users)
+	}
+	if admin, _ := registry.Configuration("admin"); admin.PageSize != 7 || admin.Sort != nil {
+		t.Errorf("admin: got %+v, want a profile apart from admin_users", admin)
+	}
+	if profile, _, _ := registry.Lookup("/admin/users/7"); profile != "admin_users" {
+		t.Errorf("admin_users route: got %q", profile)
+	}
+
+	for i, environ := range [][]string{
+		{"PAGINATION_BOOKS__PAGE_SIZE=ten"},
+		{"PAGINATION_BOOKS__ENVELOPE=hal"},
+		{"PAGINATION_BOOKS__PAGE_SIZES=10"},
+		{"PAGINATION___PAGE_SIZE=10"},
+		{"PAGINATION_ROUTES=/shops"},
+		{"PAGINATION_ROUTES=/shops=shops"},
+	} {
+		if err := registry.LoadEnv("PAGINATION_", environ); err == nil {
+			t.Errorf("%d. %v: expects an error", i, environ)
+		}
+	}
+}
+
+func TestRegistryLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pagination")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "pagination.JSON")
+	if err := ioutil.WriteFile(path, []byte(registryConfiguration), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := pagination.NewRegistry()
+	if err := registry.LoadFile(path); err != nil {
+		t.Fatalf("load file: %v", err)
+	}
+	if _, ok := registry.Profile("books"); !ok {
+		t.Errorf("load file: expects the books profile")
+	}
+
+	if err := registry.LoadFile(filepath.Join(dir, "pagination.ini")); err == nil {
+		t.Errorf("load an unregistered extension: expects an error")
+	}
+
+	pagination.RegisterDecoder(".ini", json.Unmarshal)
+	if err := registry.LoadFile(filepath.Join(dir, "pagination.ini")); !os.IsNotExist(err) {
+		t.Errorf("load a missing file: got %v, want a not-exist error", err)
+	}
+}
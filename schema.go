@@ -1,21 +1,55 @@
 package pagination
 
-import "net/url"
+import (
+	"encoding/json"
+	"net/url"
+)
 
 // PageFields defines the struct of pagination field
 type PageFields struct {
-	Page     int        `json:"page"`
-	PageSize int        `json:"page_size"`
-	Total    int        `json:"total"`
+	Page     int64      `json:"page"`
+	PageSize int64      `json:"page_size"`
+	Total    int64      `json:"total"`
 	First    string     `json:"first"`
 	Last     string     `json:"last"`
 	Prev     string     `json:"prev"`
 	Next     string     `json:"next"`
 	Query    url.Values `json:"query"`
+	Location *Location  `json:"location,omitempty"`
+	// Templated is the RFC 6570 link to any page, e.g. "/books?author=jk&page_size=5{&page}",
+	// it is emitted when a link template is configured
+	Templated string `json:"templated,omitempty"`
+	// Redirect is the link to the last page when the OutOfRangeRedirect policy applies,
+	// it is set by Wrap as well as TryWrap
+	Redirect string `json:"redirect,omitempty"`
+
+	offset   int64
+	base     int64
+	pages    int64
+	pageLink func(page int64) string
+	envelope Envelope
+}
+
+// Location defines where a located item lies in the paginated list
+type Location struct {
+	Key      string `json:"key"`
+	Index    int64  `json:"index"`
+	Position int64  `json:"position"`
 }
 
-// Paginated defines the paginated response struct
+// Paginated defines the paginated response struct,
+// it is encoded to JSON in the shape of the configured envelope
 type Paginated struct {
 	Pagination *PageFields `json:"pagination"`
 	Result     Truncatable `json:"result"`
 }
+
+// MarshalJSON encodes the paginated result in the shape of the configured envelope
+func (p Paginated) MarshalJSON() ([]byte, error) {
+	if p.Pagination != nil && p.Pagination.envelope != EnvelopeDefault {
+		return json.Marshal(p.Render(p.Pagination.envelope))
+	}
+
+	type paginated Paginated
+	return json.Marshal(paginated(p))
+}
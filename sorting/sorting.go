@@ -0,0 +1,153 @@
+// Package sorting parses and normalizes sort expressions such as `-created_at,name`.
+package sorting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Direction defines the order direction of a sorted field
+type Direction int
+
+// Directions
+const (
+	Asc Direction = iota
+	Desc
+)
+
+// String returns the SQL keyword of the direction
+func (d Direction) String() string {
+	if d == Desc {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
+// Order defines a sorted field and its direction
+type Order struct {
+	Field     string
+	Direction Direction
+}
+
+// String returns the normalized expression of the order, e.g. `-created_at`
+func (o Order) String() string {
+	if o.Direction == Desc {
+		return "-" + o.Field
+	}
+
+	return o.Field
+}
+
+// Orders defines a list of orders, the leading one has the highest precedence
+type Orders []Order
+
+// String returns the normalized expression of orders, e.g. `-created_at,name`
+func (os Orders) String() string {
+	terms := make([]string, len(os))
+	for i, o := range os {
+		terms[i] = o.String()
+	}
+
+	return strings.Join(terms, ",")
+}
+
+// SQL returns the orders in the form of an ORDER BY clause body, e.g. `created_at DESC, name ASC`
+func (os Orders) SQL() string {
+	terms := make([]string, len(os))
+	for i, o := range os {
+		terms[i] = o.Field + " " + o.Direction.String()
+	}
+
+	return strings.Join(terms, ", ")
+}
+
+// Reverse returns the orders with flipped directions, it helps querying backwards by keyset
+func (os Orders) Reverse() Orders {
+	reversed := make(Orders, len(os))
+	for i, o := range os {
+		reversed[i] = o
+		if o.Direction == Desc {
+			reversed[i].Direction = Asc
+		} else {
+			reversed[i].Direction = Desc
+		}
+	}
+
+	return reversed
+}
+
+// Has returns whether the field is in the orders
+func (os Orders) Has(field string) bool {
+	for _, o := range os {
+		if o.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnsortableFieldError is returned when a field is not in the sortable fields whitelist
+type UnsortableFieldError struct {
+	Field string
+}
+
+func (e *UnsortableFieldError) Error() string {
+	return fmt.Sprintf("sorting: field %q is not sortable", e.Field)
+}
+
+func isIdentifier(field string) bool {
+	if field == "" {
+		return false
+	}
+
+	for _, r := range field {
+		if !(r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse parses a comma separated sort expression. A leading `-` means descending order,
+// a leading `+` or no sign means ascending order.
+// If allowed is not empty, only the listed fields are sortable.
+// Unsortable and duplicated fields are dropped, the first unsortable field is reported by the error.
+func Parse(raw string, allowed []string) (orders Orders, err error) {
+	whitelist := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		whitelist[field] = true
+	}
+
+	for _, term := range strings.Split(raw, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		order := Order{Field: term, Direction: Asc}
+		switch term[0] {
+		case '-':
+			order = Order{Field: term[1:], Direction: Desc}
+		case '+':
+			order = Order{Field: term[1:], Direction: Asc}
+		}
+
+		if !isIdentifier(order.Field) || len(whitelist) > 0 && !whitelist[order.Field] {
+			if err == nil {
+				err = &UnsortableFieldError{order.Field}
+			}
+			continue
+		}
+
+		if orders.Has(order.Field) {
+			continue
+		}
+
+		orders = append(orders, order)
+	}
+
+	return
+}
@@ -0,0 +1,61 @@
+package sorting
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	allowed := []string{"created_at", "name", "year"}
+
+	tests := []struct {
+		testName   string
+		raw        string
+		normalized string
+		sql        string
+		hasErr     bool
+	}{
+		{"empty input", "", "", "", false},
+		{"single ascending field", "name", "name", "name ASC", false},
+		{"explicit ascending field", "+name", "name", "name ASC", false},
+		{"mixed directions", "-created_at,name", "-created_at,name", "created_at DESC, name ASC", false},
+		{"spaces and empty terms", " -year, ,name ", "-year,name", "year DESC, name ASC", false},
+		{"duplicated field", "name,-name", "name", "name ASC", false},
+		{"unsortable field", "-password,name", "name", "name ASC", true},
+		{"invalid identifier", "name;drop", "", "", true},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.testName)
+
+		orders, err := Parse(test.raw, allowed)
+
+		if orders.String() != test.normalized {
+			t.Errorf("%s[normalized]: got %s, want %s", descr, orders.String(), test.normalized)
+		}
+		if orders.SQL() != test.sql {
+			t.Errorf("%s[sql]: got %s, want %s", descr, orders.SQL(), test.sql)
+		}
+		if (err != nil) != test.hasErr {
+			t.Errorf("%s[error]: got %v, want error: %v", descr, err, test.hasErr)
+		}
+	}
+}
+
+func TestParseWithoutWhitelist(t *testing.T) {
+	orders, err := Parse("-anything,else", nil)
+	if err != nil || orders.String() != "-anything,else" {
+		t.Errorf("[no whitelist]: got %s, %v", orders.String(), err)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	orders := Orders{{"created_at", Desc}, {"name", Asc}}
+
+	if reversed := orders.Reverse().String(); reversed != "created_at,-name" {
+		t.Errorf("[reverse]: got %s, want %s", reversed, "created_at,-name")
+	}
+	if orders.String() != "-created_at,name" {
+		t.Errorf("[reverse mutates source]: got %s", orders.String())
+	}
+}
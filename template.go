@@ -0,0 +1,57 @@
+package pagination
+
+import (
+	"net/url"
+
+	"github.com/zheeeng/pagination/queries"
+	"github.com/zheeeng/pagination/uritemplate"
+)
+
+// SetTemplateValues sets the variables of the link template which don't come from the query, e.g. the path variables
+func (p *Paginator) SetTemplateValues(values uritemplate.Values) *Paginator {
+	p.templateValues = values
+
+	return p
+}
+
+// expansionValues returns the query parameters and the template values as the variables of the link template,
+// the repeated parameters are lists
+func (p *Paginator) expansionValues(query queryWriter) uritemplate.Values {
+	var params url.Values
+	switch q := query.(type) {
+	case url.Values:
+		params = q
+	case *queries.OrderedQuery:
+		params = q.Values()
+	}
+
+	values := uritemplate.Values{}
+	for key, items := range params {
+		switch len(items) {
+		case 0:
+		case 1:
+			values[key] = items[0]
+		default:
+			values[key] = items
+		}
+	}
+	for key, value := range p.templateValues {
+		values[key] = value
+	}
+
+	return values
+}
+
+// templatedLink returns the link template partially expanded with every variable but the page
+func (p *Paginator) templatedLink(query url.Values, pageSize int64) string {
+	cloned := url.Values{}
+	for key, values := range query {
+		cloned[key] = append([]string(nil), values...)
+	}
+	p.setQueryFields(cloned, 0, pageSize)
+
+	values := p.expansionValues(cloned)
+	delete(values, p.params.Page)
+
+	return p.template.PartialExpand(values)
+}
@@ -0,0 +1,67 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/zheeeng/pagination"
+	"github.com/zheeeng/pagination/uritemplate"
+)
+
+func TestLinkTemplate(t *testing.T) {
+	tests := []struct {
+		testName  string
+		template  string
+		link      string
+		values    uritemplate.Values
+		next      string
+		templated string
+		hasErr    bool
+	}{
+		{
+			"query template",
+			"/v2/shops/{shop}/books{?author,page,page_size}",
+			"http://books-svc/books?author=jk&page=2&page_size=5&internal=1",
+			uritemplate.Values{"shop": 42},
+			"/v2/shops/42/books?author=jk&page=3&page_size=5",
+			"/v2/shops/42/books?author=jk&page_size=5{&page}",
+			false,
+		},
+		{
+			"path segment template",
+			"https://api.example.com/books{/page}{?tag*}",
+			"/books?tag=a&tag=b&page=2&page_size=5",
+			nil,
+			"https://api.example.com/books/3?tag=a&tag=b",
+			"https://api.example.com/books{/page}?tag=a&tag=b",
+			false,
+		},
+		{
+			"invalid template",
+			"/books{?page",
+			"api.example.com/books?page=2&page_size=5",
+			nil,
+			"api.example.com/books?page=3&page_size=5",
+			"",
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		pg := pagination.NewPagination(pagination.PaginatorConfiguration{
+			Links: pagination.LinkConfiguration{Template: test.template},
+		})
+		pgt := pg.Parse(test.link).SetTemplateValues(test.values)
+
+		if err := pgt.Err(); (err != nil) != test.hasErr {
+			t.Errorf("%d. [%s] error: got %v, want error: %v", i, test.testName, err, test.hasErr)
+		}
+
+		paginated := pgt.Wrap(TrunctableBooks(books), total)
+		if paginated.Pagination.Next != test.next {
+			t.Errorf("%d. [%s] next: got %s, want %s", i, test.testName, paginated.Pagination.Next, test.next)
+		}
+		if paginated.Pagination.Templated != test.templated {
+			t.Errorf("%d. [%s] templated: got %s, want %s", i, test.testName, paginated.Pagination.Templated, test.templated)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+// Package uritemplate expands RFC 6570 URI templates, e.g. `/shops/{shop}/books{?author,page,page_size}`.
+//
+// All the four levels of the specification are supported: the simple, reserved `+`, fragment `#`, label `.`,
+// path segment `/`, path parameter `;`, query `?` and query continuation `&` expressions,
+// and the prefix `:n` and explode `*` modifiers.
+//
+// Partial expansion keeps the undefined variables as expressions,
+// e.g. `/books{?author,page}` partially expanded with author=jk results in `/books?author=jk{&page}`.
+package uritemplate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Values defines the variables of an expansion.
+// A value is a string or any scalar formatted by fmt, a list ([]string or []interface{}),
+// or an associative array (map[string]string or map[string]interface{}).
+// Nil values, empty lists and empty associative arrays are undefined.
+type Values map[string]interface{}
+
+// SyntaxError is returned when a template is malformed
+type SyntaxError struct {
+	Template string
+	Offset   int
+	Reason   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("uritemplate: %s at offset %d of %q", e.Reason, e.Offset, e.Template)
+}
+
+type operator struct {
+	char     string
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var operators = map[byte]operator{
+	'+': {"+", "", ",", false, "", true},
+	'#': {"#", "#", ",", false, "", true},
+	'.': {".", ".", ".", false, "", false},
+	'/': {"/", "/", "/", false, "", false},
+	';': {";", ";", ";", true, "", false},
+	'?': {"?", "?", "&", true, "=", false},
+	'&': {"&", "&", "&", true, "=", false},
+}
+
+var simple = operator{"", "", ",", false, "", false}
+
+const maxPrefix = 10000
+
+type varspec struct {
+	name    string
+	prefix  int
+	explode bool
+}
+
+func (v varspec) String() string {
+	switch {
+	case v.explode:
+		return v.name + "*"
+	case v.prefix > 0:
+		return v.name + ":" + strconv.Itoa(v.prefix)
+	}
+
+	return v.name
+}
+
+type expression struct {
+	raw  string
+	op   operator
+	vars []varspec
+}
+
+type part struct {
+	literal string
+	expr    *expression
+}
+
+// Template is a parsed URI template, it is safe for concurrent use
+type Template struct {
+	raw   string
+	parts []part
+}
+
+// Parse parses the URI template
+func Parse(raw string) (*Template, error) {
+	t := &Template{raw: raw}
+
+	for offset := 0; offset < len(raw); {
+		open := strings.IndexAny(raw[offset:], "{}")
+		if open < 0 {
+			t.parts = append(t.parts, part{literal: raw[offset:]})
+			break
+		}
+		open += offset
+		if raw[open] == '}' {
+			return nil, &SyntaxError{raw, open, "unmatched '}'"}
+		}
+		if open > offset {
+			t.parts = append(t.parts, part{literal: raw[offset:open]})
+		}
+
+		end := strings.IndexByte(raw[open:], '}')
+		if end < 0 {
+			return nil, &SyntaxError{raw, open, "unclosed expression"}
+		}
+		end += open
+
+		expr, err := parseExpression(raw, open)
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, part{expr: expr})
+
+		offset = end + 1
+	}
+
+	return t, nil
+}
+
+// MustParse does the same thing with Parse, and it panics if the template is malformed
+func MustParse(raw string) *Template {
+	t, err := Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func parseExpression(raw string, open int) (*expression, error) {
+	end := open + strings.IndexByte(raw[open:], '}')
+	body := raw[open+1 : end]
+	expr := &expression{raw: raw[open : end+1], op: simple}
+
+	offset := open + 1
+	if body != "" {
+		if op, ok := operators[body[0]]; ok {
+			expr.op = op
+			body = body[1:]
+			offset++
+		} else if strings.IndexByte("=,!@|", body[0]) >= 0 {
+			return nil, &SyntaxError{raw, offset, "reserved operator"}
+		}
+	}
+
+	for _, spec := range strings.Split(body, ",") {
+		v := varspec{name: spec}
+		if strings.HasSuffix(spec, "*") {
+			v = varspec{name: spec[:len(spec)-1], explode: true}
+		} else if colon := strings.IndexByte(spec, ':'); colon >= 0 {
+			prefix, err := strconv.Atoi(spec[colon+1:])
+			if err != nil || prefix <= 0 || prefix >= maxPrefix || spec[colon+1] == '0' {
+				return nil, &SyntaxError{raw, offset + colon + 1, "invalid prefix"}
+			}
+			v = varspec{name: spec[:colon], prefix: prefix}
+		}
+
+		if !isVarname(v.name) {
+			return nil, &SyntaxError{raw, offset, fmt.Sprintf("invalid variable name %q", v.name)}
+		}
+		expr.vars = append(expr.vars, v)
+		offset += len(spec) + 1
+	}
+
+	return expr, nil
+}
+
+func isVarname(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case c == '%' && i+2 < len(name) && isHex(name[i+1]) && isHex(name[i+2]):
+			i += 2
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// String returns the raw template
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Variables returns the variable names in the order they appear in the template, without duplicates
+func (t *Template) Variables() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, p := range t.parts {
+		if p.expr == nil {
+			continue
+		}
+		for _, v := range p.expr.vars {
+			if !seen[v.name] {
+				seen[v.name] = true
+				names = append(names, v.name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Expand expands the template, the undefined variables are expanded to nothing
+func (t *Template) Expand(values Values) string {
+	return t.expand(values, false)
+}
+
+// PartialExpand expands the defined variables and keeps the undefined ones as expressions.
+// The expressions of the simple, reserved and fragment operators are kept whole if any of their variables is undefined.
+func (t *Template) PartialExpand(values Values) string {
+	return t.expand(values, true)
+}
+
+func (t *Template) expand(values Values, partial bool) string {
+	var buf strings.Builder
+	for _, p := range t.parts {
+		if p.expr == nil {
+			buf.WriteString(encodeLiteral(p.literal))
+			continue
+		}
+		buf.WriteString(p.expr.expand(values, partial))
+	}
+
+	return buf.String()
+}
+
+func (e *expression) expand(values Values, partial bool) string {
+	var pieces []string
+	var undefined []varspec
+
+	for _, v := range e.vars {
+		piece, ok := e.op.expandVar(v, values[v.name])
+		if !ok {
+			undefined = append(undefined, v)
+			continue
+		}
+		pieces = append(pieces, piece)
+	}
+
+	if partial && len(undefined) > 0 {
+		switch e.op.char {
+		case "", "+", "#":
+			return e.raw
+		}
+	}
+
+	expanded := ""
+	if len(pieces) > 0 {
+		expanded = e.op.first + strings.Join(pieces, e.op.sep)
+	}
+
+	if !partial || len(undefined) == 0 {
+		return expanded
+	}
+
+	char := e.op.char
+	if char == "?" && len(pieces) > 0 {
+		char = "&"
+	}
+	specs := make([]string, len(undefined))
+	for i, v := range undefined {
+		specs[i] = v.String()
+	}
+
+	return expanded + "{" + char + strings.Join(specs, ",") + "}"
+}
+
+type pair struct {
+	key   string
+	value string
+}
+
+// expandVar returns the expansion of the variable, ok is false if the variable is undefined
+func (op operator) expandVar(v varspec, value interface{}) (piece string, ok bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case []string:
+		if len(value) == 0 {
+			return "", false
+		}
+		return op.expandList(v, value), true
+	case []interface{}:
+		if len(value) == 0 {
+			return "", false
+		}
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return op.expandList(v, items), true
+	case map[string]string:
+		if len(value) == 0 {
+			return "", false
+		}
+		pairs := make([]pair, 0, len(value))
+		for key, item := range value {
+			pairs = append(pairs, pair{key, item})
+		}
+		return op.expandPairs(v, pairs), true
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return "", false
+		}
+		pairs := make([]pair, 0, len(value))
+		for key, item := range value {
+			pairs = append(pairs, pair{key, fmt.Sprint(item)})
+		}
+		return op.expandPairs(v, pairs), true
+	}
+
+	s := fmt.Sprint(value)
+	if v.prefix > 0 && utf8.RuneCountInString(s) > v.prefix {
+		runes := []rune(s)
+		s = string(runes[:v.prefix])
+	}
+
+	return op.expandValue(v.name, s), true
+}
+
+func (op operator) expandValue(name, value string) string {
+	if !op.named {
+		return op.encode(value)
+	}
+	if value == "" {
+		return name + op.ifEmpty
+	}
+
+	return name + "=" + op.encode(value)
+}
+
+func (op operator) expandList(v varspec, items []string) string {
+	if v.explode {
+		pieces := make([]string, len(items))
+		for i, item := range items {
+			pieces[i] = op.expandValue(v.name, item)
+		}
+		return strings.Join(pieces, op.sep)
+	}
+
+	encoded := make([]string, len(items))
+	for i, item := range items {
+		encoded[i] = op.encode(item)
+	}
+	if op.named {
+		return v.name + "=" + strings.Join(encoded, ",")
+	}
+
+	return strings.Join(encoded, ",")
+}
+
+func (op operator) expandPairs(v varspec, pairs []pair) string {
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+
+	if v.explode {
+		pieces := make([]string, len(pairs))
+		for i, p := range pairs {
+			if op.named && p.value == "" {
+				pieces[i] = op.encode(p.key) + op.ifEmpty
+			} else {
+				pieces[i] = op.encode(p.key) + "=" + op.encode(p.value)
+			}
+		}
+		return strings.Join(pieces, op.sep)
+	}
+
+	encoded := make([]string, 0, 2*len(pairs))
+	for _, p := range pairs {
+		encoded = append(encoded, op.encode(p.key), op.encode(p.value))
+	}
+	if op.named {
+		return v.name + "=" + strings.Join(encoded, ",")
+	}
+
+	return strings.Join(encoded, ",")
+}
+
+func isUnreserved(c byte) bool {
+	return c == '-' || c == '.' || c == '_' || c == '~' ||
+		c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+const upperhex = "0123456789ABCDEF"
+
+// encode percent-encodes the value, the reserved characters and pct-encoded triplets are kept by the reserved operators
+func (op operator) encode(value string) string {
+	var buf strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case isUnreserved(c):
+			buf.WriteByte(c)
+		case op.reserved && isReserved(c):
+			buf.WriteByte(c)
+		case op.reserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			buf.WriteString(value[i : i+3])
+			i += 2
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(upperhex[c>>4])
+			buf.WriteByte(upperhex[c&15])
+		}
+	}
+
+	return buf.String()
+}
+
+// encodeLiteral percent-encodes the characters not allowed in a URI
+func encodeLiteral(literal string) string {
+	return operators['+'].encode(literal)
+}
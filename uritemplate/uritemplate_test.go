@@ -0,0 +1,167 @@
+package uritemplate
+
+import (
+	"fmt"
+	"testing"
+)
+
+// the variables of the RFC 6570 examples
+var rfcValues = Values{
+	"count":      []string{"one", "two", "three"},
+	"dom":        []string{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []string{"red", "green", "blue"},
+	"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+	"v":          6,
+	"x":          1024,
+	"y":          768,
+	"empty":      "",
+	"empty_keys": map[string]string{},
+	"undef":      nil,
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		template string
+		expanded string
+	}{
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{half}", "50%25"},
+		{"O{empty}X", "OX"},
+		{"O{undef}X", "OX"},
+		{"{x,y}", "1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"?{x,empty}", "?1024,"},
+		{"?{x,undef}", "?1024"},
+		{"{var:3}", "val"},
+		{"{var:30}", "value"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+		{"{+var}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+half}", "50%25"},
+		{"{base}index", "http%3A%2F%2Fexample.com%2Fhome%2Findex"},
+		{"{+base}index", "http://example.com/home/index"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"{+path:6}/here", "/foo/b/here"},
+		{"{+keys*}", "comma=,,dot=.,semi=;"},
+		{"{#var}", "#value"},
+		{"{#hello}", "#Hello%20World!"},
+		{"{#half}", "#50%25"},
+		{"foo{#empty}", "foo#"},
+		{"foo{#undef}", "foo"},
+		{"{#x,hello,y}", "#1024,Hello%20World!,768"},
+		{"{#list*}", "#red,green,blue"},
+		{"X{.var}", "X.value"},
+		{"X{.x,y}", "X.1024.768"},
+		{"{.who,who}", ".fred.fred"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"X{.empty_keys}", "X"},
+		{"{/var}", "/value"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/var:1,var}", "/v/value"},
+		{"{/list*}", "/red/green/blue"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{/keys*}", "/comma=%2C/dot=./semi=%3B"},
+		{"{;who}", ";who=fred"},
+		{"{;half}", ";half=50%25"},
+		{"{;empty}", ";empty"},
+		{"{;v,empty,who}", ";v=6;empty;who=fred"},
+		{"{;list}", ";list=red,green,blue"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{;keys*}", ";comma=%2C;dot=.;semi=%3B"},
+		{"{?who}", "?who=fred"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?list*}", "?list=red&list=green&list=blue"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{&var:3}", "&var=val"},
+		{"{&list*}", "&list=red&list=green&list=blue"},
+		{"/über{?who}", "/%C3%BCber?who=fred"},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.template)
+
+		template, err := Parse(test.template)
+		if err != nil {
+			t.Errorf("%s[parse]: %v", descr, err)
+			continue
+		}
+		if expanded := template.Expand(rfcValues); expanded != test.expanded {
+			t.Errorf("%s[expanded]: got %s, want %s", descr, expanded, test.expanded)
+		}
+	}
+}
+
+func TestPartialExpand(t *testing.T) {
+	tests := []struct {
+		template string
+		values   Values
+		expanded string
+	}{
+		{"/books{?author,page,page_size}", Values{"author": "jk", "page_size": 5}, "/books?author=jk&page_size=5{&page}"},
+		{"/books{?page}", Values{}, "/books{?page}"},
+		{"/books{?page}{&sort}", Values{"sort": "-id"}, "/books{?page}&sort=-id"},
+		{"/shops/{shop}/books{/page}", Values{"shop": 42}, "/shops/42/books{/page}"},
+		{"{/a,b:2,c*}", Values{"a": "x"}, "/x{/b:2,c*}"},
+		{"{+base}{x,y}", Values{"base": "/v2/", "x": 1}, "/v2/{x,y}"},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.template)
+
+		expanded := MustParse(test.template).PartialExpand(test.values)
+		if expanded != test.expanded {
+			t.Errorf("%s[expanded]: got %s, want %s", descr, expanded, test.expanded)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		template  string
+		variables string
+		hasErr    bool
+	}{
+		{"/books", "[]", false},
+		{"/shops/{shop}/books{?author,page,shop}", "[shop author page]", false},
+		{"{var%20name}", "[var%20name]", false},
+		{"{unclosed", "", true},
+		{"closed}", "", true},
+		{"{}", "", true},
+		{"{=reserved}", "", true},
+		{"{bad name}", "", true},
+		{"{.dot.}", "", true},
+		{"{var:0}", "", true},
+		{"{var:10000}", "", true},
+		{"{var:}", "", true},
+		{"{a{b}", "", true},
+	}
+
+	for i, test := range tests {
+		descr := fmt.Sprintf("\n%d. Test %s failed:\n", i, test.template)
+
+		template, err := Parse(test.template)
+		if (err != nil) != test.hasErr {
+			t.Errorf("%s[error]: got %v, want error: %v", descr, err, test.hasErr)
+		}
+		if _, ok := err.(*SyntaxError); err != nil && !ok {
+			t.Errorf("%s[error type]: got %T, want *SyntaxError", descr, err)
+		}
+		if err == nil && fmt.Sprint(template.Variables()) != test.variables {
+			t.Errorf("%s[variables]: got %v, want %s", descr, template.Variables(), test.variables)
+		}
+	}
+}